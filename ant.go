@@ -2,7 +2,7 @@ package main
 
 import (
 	"fmt"
-	"math/rand"
+	"math"
 )
 
 type direction int
@@ -77,17 +77,66 @@ func (p point) Within(x, y, w, h int) bool {
 	return p.x >= x && p.x < x+w && p.y >= y && p.y < y+h
 }
 
+// Ant is the state of one ant, as it's added to and read back from an
+// antStore.
 type Ant struct {
 	pos    point
 	dir    direction
 	food   int
 	marker int
 	life   int
+	caste  caste
+	genome genome
+	id     uint64
+	age    int
+
+	// Used by the continuous movement model instead of pos and dir, which
+	// are kept up to date with them.
+	fx, fy  float64
+	heading float64
+
+	delivered int    // Food delivered to the hive this tick, collected by the scene
+	rand      uint64 // State of the ant's own random source
+	tagged    bool   // The ant's trail is being traced
+
+	trips       int     // Trips completed
+	tripDist    float64 // Distance travelled on the current trip
+	lastTrip    float64 // Distance travelled on the trip just completed, collected with delivered
+	sincePickup int     // Ticks since the ant last picked up food, or hatched
+	pickupAt    point   // Where the ant last picked up food
+	homeSteps   int     // Cells moved through since picking up food, while carrying it
+}
+
+// next returns the next number from the ant's own random source, a
+// splitmix64 generator. Each ant drawing from its own source means ants can be
+// moved in parallel and still draw the same numbers as when moved serially.
+func (s *antStore) next(i int) uint64 {
+	s.rand[i] += 0x9e3779b97f4a7c15
+	z := s.rand[i]
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
 }
 
-func (a *Ant) GridAt(an *AntScene, d direction) (gridspot, bool) {
-	np := a.pos.PointAt(d)
-	if np.Within(0, 0, an.st.width, an.st.height) {
+// intn returns a random number in [0,n) from the ant's source.
+func (s *antStore) intn(i, n int) int {
+	return int(s.next(i) % uint64(n))
+}
+
+// float64 returns a random number in [0,1) from the ant's source.
+func (s *antStore) float64(i int) float64 {
+	return float64(s.next(i)>>11) / (1 << 53)
+}
+
+// blocked reports whether the next cell from pos in direction d is a wall or
+// off the field. It's GridAt without copying the cell, for Move.
+func (an *AntScene) blocked(pos point, d direction) bool {
+	np, ok := an.cell(an.step(pos, d))
+	return !ok || an.field.vals[np.x+np.y*an.field.width].Wall
+}
+
+func (an *AntScene) GridAt(pos point, d direction) (gridspot, bool) {
+	if np, ok := an.cell(an.step(pos, d)); ok {
 		//return an.grid[np.x][np.y], true
 		return *an.field.Get(np.x, np.y), true
 	}
@@ -162,191 +211,131 @@ func (d direction) Right(n int) direction {
 // 	return sdl.Rect{int32(start.x), int32(start.y), int32(end.x - start.x), int32(end.y - start.y)}
 // }
 
-func (a *Ant) Line(an *AntScene, d direction, size int) gridspot {
+// dirSteps is how far one step in each direction goes on the square grid.
+var dirSteps = [END]point{N: {0, -1}, NE: {1, -1}, E: {1, 0}, SE: {1, 1}, S: {0, 1}, SW: {-1, 1}, W: {-1, 0}, NW: {-1, -1}}
+
+// Line sums the cells from p out to size cells in direction d, stopping at
+// the first wall or the edge of the field, which mark it as a wall.
+func (an *AntScene) Line(p point, d direction, size int) gridspot {
+	if d < N || d >= END {
+		panic("NO SUCH DIRECTION")
+	}
+	if an.hexGrid() {
+		return an.hexLine(p, d, size)
+	}
+	// Every step on the square grid is the same, so the cells are walked
+	// by index. This is the hottest loop in the simulation.
+	f := an.field
+	ds := dirSteps[d]
+	x, y := p.x, p.y
 	var pt gridspot
-	addspot := func(g *gridspot) bool {
+	for i := 0; i < size; i++ {
+		if x < 0 || x >= f.width || y < 0 || y >= f.height {
+			if !an.st.wrap {
+				pt.Wall = true
+				break
+			}
+			x, y = wrapi(x, f.width), wrapi(y, f.height)
+		}
+		g := &f.vals[x+y*f.width]
 		if g.Wall {
 			pt.Wall = true
-			return false
+			break
 		}
 		if g.Food < 0 {
 			panic("g.FOOD < 0 \n")
 		}
-		pt.FoodPher += g.FoodPher + g.Food*pheromoneMax*2
-		pt.HomePher += g.HomePher
-		if g.Home {
-			pt.HomePher += pheromoneMax * 2
-		}
-		return true
+		pt.addSample(g, an.st.saturate)
+		x += ds.x
+		y += ds.y
 	}
-	switch d {
-	case N:
-		for y := a.pos.y; y > a.pos.y-size; y-- {
-			if y < 0 {
-				pt.Wall = true
-				break
-			}
-			if !addspot(an.field.Get(a.pos.x, y)) {
-				break
-			}
-		}
-	case NE:
-		for i := 0; i < size; i++ {
-			y := a.pos.y - i
-			x := a.pos.x + i
-			if y < 0 || x >= an.field.width {
-				pt.Wall = true
-				break
-			}
-			if !addspot(an.field.Get(x, y)) {
-				break
-			}
-		}
-	case E:
-		for x := a.pos.x; x < a.pos.x+size; x++ {
-			if x >= an.field.width {
-				pt.Wall = true
-				break
-			}
-			if !addspot(an.field.Get(x, a.pos.y)) {
-				break
-			}
-		}
-	case SE:
-		for i := 0; i < size; i++ {
-			y := a.pos.y + i
-			x := a.pos.x + i
-			if y >= an.field.height || x >= an.field.width {
-				pt.Wall = true
-				break
-			}
-			if !addspot(an.field.Get(x, y)) {
-				break
-			}
-		}
-	case S:
-		for y := a.pos.y; y < a.pos.y+size; y++ {
-			if y >= an.field.height {
-				pt.Wall = true
-				break
-			}
-			if !addspot(an.field.Get(a.pos.x, y)) {
-				break
-			}
-		}
-	case SW:
-		for i := 0; i < size; i++ {
-			y := a.pos.y + i
-			x := a.pos.x - i
-			if y >= an.field.height || x < 0 {
-				pt.Wall = true
-				break
-			}
-			if !addspot(an.field.Get(x, y)) {
-				break
-			}
+	return pt
+}
+
+// hexLine is Line on the hex grid, where steps depend on the row.
+func (an *AntScene) hexLine(p point, d direction, size int) gridspot {
+	var pt gridspot
+	for i := 0; i < size; i++ {
+		c, ok := an.cell(p)
+		if !ok {
+			pt.Wall = true
+			break
 		}
-	case W:
-		for x := a.pos.x; x > a.pos.x-size; x-- {
-			if x < 0 {
-				pt.Wall = true
-				break
-			}
-			if !addspot(an.field.Get(x, a.pos.y)) {
-				break
-			}
+		g := an.field.Get(c.x, c.y)
+		if g.Wall {
+			pt.Wall = true
+			break
 		}
-	case NW:
-		for i := 0; i < size; i++ {
-			y := a.pos.y - i
-			x := a.pos.x - i
-			if y < 0 || x < 0 {
-				pt.Wall = true
-				break
-			}
-			if !addspot(an.field.Get(x, y)) {
-				break
-			}
+		if g.Food < 0 {
+			panic("g.FOOD < 0 \n")
 		}
-	default:
-		panic("NO SUCH DIRECTION")
+		pt.addSample(g, an.st.saturate)
+		p = an.step(p, d)
 	}
 	return pt
 }
 
-func (a *Ant) SumOctant(g *GameState, an *AntScene, d direction, size int) gridspot {
+// SumOctant sums the cells in the square of size cells facing d. Walls in the
+// square are skipped, and it's only marked as a wall if the next cell towards
+// d is one, so a far off wall doesn't block the whole direction.
+func (an *AntScene) SumOctant(pos point, d direction, size int) gridspot {
 	var (
 		start point
 		end   point
 	)
 	switch d {
 	case N:
-		start.x = a.pos.x - (size / 2)
-		start.y = a.pos.y - size
-		end.x = a.pos.x + (size / 2)
-		end.y = a.pos.y
+		start.x = pos.x - (size / 2)
+		start.y = pos.y - size
+		end.x = pos.x + (size / 2)
+		end.y = pos.y
 	case NE:
-		start.x = a.pos.x
-		start.y = a.pos.y - size
-		end.x = a.pos.x + size
-		end.y = a.pos.y
+		start.x = pos.x
+		start.y = pos.y - size
+		end.x = pos.x + size
+		end.y = pos.y
 	case E:
-		start.x = a.pos.x
-		start.y = a.pos.y - (size / 2)
-		end.x = a.pos.x + size
-		end.y = a.pos.y + (size / 2)
+		start.x = pos.x
+		start.y = pos.y - (size / 2)
+		end.x = pos.x + size
+		end.y = pos.y + (size / 2)
 	case SE:
-		start.x = a.pos.x
-		start.y = a.pos.y
-		end.x = a.pos.x + size
-		end.y = a.pos.y + size
+		start.x = pos.x
+		start.y = pos.y
+		end.x = pos.x + size
+		end.y = pos.y + size
 	case S:
-		start.x = a.pos.x - (size / 2)
-		start.y = a.pos.y
-		end.x = a.pos.x + (size / 2)
-		end.y = a.pos.y + size
+		start.x = pos.x - (size / 2)
+		start.y = pos.y
+		end.x = pos.x + (size / 2)
+		end.y = pos.y + size
 	case SW:
-		start.x = a.pos.x - size
-		start.y = a.pos.y
-		end.x = a.pos.x
-		end.y = a.pos.y + size
+		start.x = pos.x - size
+		start.y = pos.y
+		end.x = pos.x
+		end.y = pos.y + size
 	case W:
-		start.x = a.pos.x - size
-		start.y = a.pos.y - (size / 2)
-		end.x = a.pos.x
-		end.y = a.pos.y + (size / 2)
+		start.x = pos.x - size
+		start.y = pos.y - (size / 2)
+		end.x = pos.x
+		end.y = pos.y + (size / 2)
 	case NW:
-		start.x = a.pos.x - size
-		start.y = a.pos.y - size
-		end.x = a.pos.x
-		end.y = a.pos.y
+		start.x = pos.x - size
+		start.y = pos.y - size
+		end.x = pos.x
+		end.y = pos.y
 	}
 	var pt gridspot
 	for y := start.y; y < end.y; y++ {
 		for x := start.x; x < end.x; x++ {
-
-			p := point{x, y}
-			//if p.Within(0, 0, WIDTH, HEIGHT) && !an.grid[x][y].Wall
-			if p.Within(0, 0, an.st.width, an.st.height) {
-				//spot := an.field.Get(x, y)
-				if !an.field.vals[x+y*an.field.width].Wall {
-					pt.FoodPher += an.field.vals[x+y*an.field.width].FoodPher + an.field.vals[x+y*an.field.width].Food*100000 // - (an.grid[x][y].homePher / 4)
-					pt.HomePher += an.field.vals[x+y*an.field.width].HomePher                                                 // - (an.grid[x][y].foodPher / 4)
-					if an.field.vals[x+y*an.field.width].Home {
-						pt.HomePher += 100000
-					}
-				}
+			p, ok := an.cell(point{x, y})
+			if ok && !an.field.vals[p.x+p.y*an.field.width].Wall {
+				pt.addSample(&an.field.vals[p.x+p.y*an.field.width], an.st.saturate)
 			}
 		}
 	}
-	// if an.pherOverload {
-	// 	if pt.FoodPher > pheromoneMax*an.pherOverloadFactor {
-	// 		pt.FoodPher = pheromoneMax * an.pherOverloadFactor
-	// 	}
-	// 	if pt.HomePher > pheromoneMax*an.pherOverloadFactor {
-	// 		pt.HomePher = pheromoneMax * an.pherOverloadFactor
-	// 	}
-	// }
+	next, ok := an.cell(an.step(pos, d))
+	pt.Wall = !ok || an.field.Get(next.x, next.y).Wall
 	return pt
 }
 
@@ -358,20 +347,34 @@ func antFadeDivisor(d int) int {
 	return d
 }
 
-// Returns whether or not the ant is alive
-func (a *Ant) Update(as *AntScene) bool {
-	a.Move(as)
-	if a.life <= 0 {
-		//as.field.Get(a.pos.x, a.pos.y).Food = 1
-		// if a.food > 0 {
-		// 	as.field.Get(a.pos.x, a.pos.y).Food += a.food
-		// }
-		return false
+// Update moves the ant. It only reads the field, so any number of ants can be
+// updated at once. Returns whether or not the ant is alive
+func (s *antStore) Update(as *AntScene, i int, cg *[endCaste]genome) bool {
+	s.age[i]++
+	s.sincePickup[i]++
+	p, fx, fy := s.pos[i], s.fx[i], s.fy[i]
+	s.Move(as, i, cg)
+	s.tripDist[i] += s.moved(as, i, p, fx, fy)
+	if s.food[i] > 0 && s.pos[i] != p {
+		s.homeSteps[i]++
 	}
-	if as.field.Get(a.pos.x, a.pos.y).Home {
-		if a.food > 0 {
-			as.homelife += int64(a.food) * int64(as.st.foodlife)
-			a.food = 0
+	return s.life[i] > 0
+}
+
+// Commit has the ant act on the cell it's in: delivering or picking up food
+// and laying pheromone. It changes nothing but the ant and its own cell, so
+// ants in different cells can be committed at once. Food delivered to the
+// hive is left in delivered for the scene to collect.
+func (s *antStore) Commit(as *AntScene, i int, cg *[endCaste]genome) {
+	gn := s.genes(as, i, cg)
+	as.heat.record(layerVisits, s.pos[i], s.headingOf(as, i))
+	if as.field.Get(s.pos[i].x, s.pos[i].y).Home {
+		if s.food[i] > 0 {
+			s.delivered[i] = s.food[i]
+			s.food[i] = 0
+			s.trips[i]++
+			s.lastTrip[i] = s.tripDist[i]
+			s.tripDist[i] = 0
 		}
 		// need := int64(antlife - a.life)
 		// if need > as.homefood {
@@ -379,55 +382,61 @@ func (a *Ant) Update(as *AntScene) bool {
 		// }
 		// as.homefood -= need
 		// a.life += int(need)
-		a.marker = marker
+		s.marker[i] = gn.marker
 	}
-	if spot := as.field.Get(a.pos.x, a.pos.y); spot.Food > 0 {
-		if a.food == 0 {
-			a.dir = a.dir.Right(4)
-			if spot.Food > 10 {
-				spot.Food -= 10
-				as.field.Update(a.pos.x, a.pos.y)
-				a.food = 10
+	if spot := as.field.Get(s.pos[i].x, s.pos[i].y); spot.Food > 0 {
+		if s.food[i] == 0 {
+			as.heat.record(layerPickups, s.pos[i], 0)
+			s.sincePickup[i] = 0
+			s.pickupAt[i] = s.pos[i]
+			s.homeSteps[i] = 0
+			s.turnAround(as, i)
+			carry := as.st.castes[s.caste[i]].carry
+			if spot.Food > carry {
+				spot.Food -= carry
+				as.field.Update(s.pos[i].x, s.pos[i].y)
+				s.food[i] = carry
 			} else {
-				a.food = spot.Food
+				s.food[i] = spot.Food
 				spot.Food = 0
-				as.field.Update(a.pos.x, a.pos.y)
+				as.field.Update(s.pos[i].x, s.pos[i].y)
 			}
 		}
-		a.marker = marker
+		s.marker[i] = gn.marker
 	}
 
-	if a.food > 0 {
-		spot := as.field.Get(a.pos.x, a.pos.y)
-		if spot.FoodPher > a.marker {
-			a.marker = spot.FoodPher
-			a.marker -= (a.marker / antFadeDivisor(as.st.fadedivisor)) + 1
+	if s.food[i] > 0 {
+		spot := as.field.Get(s.pos[i].x, s.pos[i].y)
+		if spot.FoodPher > s.marker[i] {
+			s.marker[i] = spot.FoodPher
+			s.marker[i] -= (s.marker[i] / antFadeDivisor(gn.fade)) + 1
 		} else {
-			spot.FoodPher = a.marker
-			a.marker -= (a.marker / antFadeDivisor(as.st.fadedivisor)) + 1
+			spot.FoodPher = s.marker[i]
+			as.pher.mark(s.pos[i].x, s.pos[i].y)
+			s.marker[i] -= (s.marker[i] / antFadeDivisor(gn.fade)) + 1
 			if as.st.renderPher {
-				as.field.Update(a.pos.x, a.pos.y)
+				as.field.Update(s.pos[i].x, s.pos[i].y)
 			}
 		}
 	} else {
-		spot := as.field.Get(a.pos.x, a.pos.y)
-		if spot.HomePher > a.marker {
-			a.marker = spot.HomePher
-			a.marker -= (a.marker / antFadeDivisor(as.st.fadedivisor)) + 1
+		spot := as.field.Get(s.pos[i].x, s.pos[i].y)
+		if spot.HomePher > s.marker[i] {
+			s.marker[i] = spot.HomePher
+			s.marker[i] -= (s.marker[i] / antFadeDivisor(gn.fade)) + 1
 		} else {
-			spot.HomePher = a.marker
-			a.marker -= (a.marker / antFadeDivisor(as.st.fadedivisor)) + 1
+			spot.HomePher = s.marker[i]
+			as.pher.mark(s.pos[i].x, s.pos[i].y)
+			s.marker[i] -= (s.marker[i] / antFadeDivisor(gn.fade)) + 1
 			if as.st.renderPher {
-				as.field.Update(a.pos.x, a.pos.y)
+				as.field.Update(s.pos[i].x, s.pos[i].y)
 			}
 		}
 	}
-	return true
 }
 
-func (a *Ant) Move(an *AntScene) {
-	a.life -= 1
-	if a.life <= 0 {
+func (s *antStore) Move(an *AntScene, i int, cg *[endCaste]genome) {
+	s.life[i] -= 1
+	if s.life[i] <= 0 {
 		return
 		// if a.food > 0 {
 		// 	a.food--
@@ -436,136 +445,186 @@ func (a *Ant) Move(an *AntScene) {
 		// 	return
 		// }
 	}
+	p := an.st.castes[s.caste[i]]
+	if p.slow > 1 && s.life[i]%p.slow != 0 {
+		return
+	}
+	gn := s.genes(an, i, cg)
 
 	// We need ants to not always follow exactly the right path, or else they
 	// get stuck following very tight lines, and never explore.
 	//fmt.Printf("Dizziness: %d\n", a.dizziness)
-	if n := rand.Intn(10); n == 0 {
-		// straight := a.SumOctant(an, a.dir, 50)
-		// left := a.SumOctant(an, a.dir.Left(1), 50)
-		// right := a.SumOctant(an, a.dir.Right(1), 50)
-
-		//const sight = 50
-		//const sight = 10
-		straight := a.Line(an, a.dir, an.st.sight)
-		left := a.Line(an, a.dir.Left(1), an.st.sight)
-		lleft := a.Line(an, a.dir.Left(2), an.st.sight)
-		right := a.Line(an, a.dir.Right(1), an.st.sight)
-		rright := a.Line(an, a.dir.Right(2), an.st.sight)
-
-		if straight.FoodPher < 0 || right.FoodPher < 0 || left.FoodPher < 0 {
-			panic(fmt.Sprintf("Ant(%d,%d,%d): Less that zero: straight: %#v, left: %#v, right: %#v, lleft: %#v, rright: %#v",
-				a.pos.x, a.pos.y, a.dir, straight, left, right, lleft, rright))
-		}
+	if s.intn(i, 100) < gn.senseOdds {
+		pr := s.sense(an, i, gn.sight)
+		wander := p.wander > 0 && s.intn(i, 10) < p.wander
+		turn := s.steer(an, i, &gn, pr, wander)
 
-		// Directions include weighted values of their left and right directions
-		straight.FoodPher += left.FoodPher/2 + right.FoodPher/2
-		straight.HomePher += left.HomePher/2 + right.HomePher/2
-		left.FoodPher += lleft.FoodPher/2 + straight.FoodPher/2
-		left.HomePher += lleft.HomePher/2 + straight.HomePher/2
-		right.FoodPher += rright.FoodPher/2 + straight.FoodPher/2
-		right.HomePher += rright.HomePher/2 + straight.HomePher/2
-
-		followingPher := false
-		if a.food > 0 { //|| a.life < antlife/2 { // go home if we have food or we need food
-			// if rightPower > straightPower && rightPower > leftPower {
-			// 	a.dir = a.dir.Right(1)
-			// 	followingPher = true
-			// } else if leftPower > straightPower && leftPower > rightPower {
-			// 	a.dir = a.dir.Left(1)
-			// 	followingPher = true
-			// }
-			if right.HomePher > straight.HomePher && right.HomePher > left.HomePher {
-				a.dir = a.dir.Right(1)
-				followingPher = true
-			} else if left.HomePher > straight.HomePher && left.HomePher > right.HomePher {
-				a.dir = a.dir.Left(1)
-				followingPher = true
-			} else if straight.HomePher > left.HomePher && straight.HomePher > right.HomePher {
-				followingPher = true
+		if an.st.continuous {
+			s.heading[i] += float64(turn) * math.Pi / 4
+			// Random turns are by any angle up to a direction either way,
+			// so headings don't stay lined up with the grid.
+			if s.intn(i, 100) < gn.turnOdds {
+				s.heading[i] += (s.float64(i)*2 - 1) * math.Pi / 4
 			}
+			s.dir[i] = angleDir(s.heading[i])
 		} else {
-			if an.st.antisocial {
-				if straight.Wall {
-					straight.HomePher += pheromoneMax * an.st.sight
-				}
-				if left.Wall {
-					left.HomePher += pheromoneMax * an.st.sight
-				}
-				if right.Wall {
-					right.HomePher += pheromoneMax * an.st.sight
-				}
-				straightPower := straight.HomePher - (straight.FoodPher * 2)
-				leftPower := left.HomePher - (left.FoodPher * 2)
-				rightPower := right.HomePher - (right.FoodPher * 2)
-
-				if rightPower < straightPower && rightPower < leftPower {
-					a.dir = a.dir.Right(1)
-					//followingPher = true
-				} else if leftPower < straightPower && leftPower < rightPower {
-					a.dir = a.dir.Left(1)
-					//followingPher = true
-				}
-			} else {
-				if right.FoodPher > straight.FoodPher && right.FoodPher > left.FoodPher {
-					a.dir = a.dir.Right(1)
-					followingPher = true
-				} else if left.FoodPher > straight.FoodPher && left.FoodPher > right.FoodPher {
-					a.dir = a.dir.Left(1)
-					followingPher = true
-				} else if straight.FoodPher > left.FoodPher && straight.FoodPher > right.FoodPher {
-					followingPher = true
-				}
-			}
-		}
+			s.dir[i] = an.right(s.dir[i], turn)
 
-		if an.st.followWalls {
-			if !followingPher {
-				if lleft.Wall {
-					if left.Wall {
-						if straight.Wall {
-							a.dir = a.dir.Right(1)
-						}
-					} else {
-						a.dir = a.dir.Left(1)
-					}
-				}
-				if rright.Wall {
-					if right.Wall {
-						if straight.Wall {
-							a.dir = a.dir.Left(1)
-						}
-					} else {
-						a.dir = a.dir.Right(1)
-					}
-				}
+			// Take a random turn every once in a while
+			n := s.intn(i, 100)
+			if n < gn.turnOdds/2 {
+				s.dir[i] = an.left(s.dir[i], 1)
+			} else if n < gn.turnOdds {
+				s.dir[i] = an.right(s.dir[i], 1)
 			}
 		}
+	}
 
-		// Take a random turn every once in a while
-		n := rand.Intn(10)
-		if n == 0 {
-			a.dir = a.dir.Left(1)
-		} else if n == 1 {
-			a.dir = a.dir.Right(1)
-		}
+	if an.st.continuous {
+		s.glide(an, i)
+		return
 	}
 
-	if g, ok := a.GridAt(an, a.dir); !ok || g.Wall {
-		a.dir = a.dir.Right((rand.Intn(3) - 1) * 2)
-		g, ok := a.GridAt(an, a.dir)
-		i := 0
-		for ; !ok || g.Wall; g, ok = a.GridAt(an, a.dir) {
-			a.dir = a.dir.Right((rand.Intn(3) - 1) * 2)
+	if an.blocked(s.pos[i], s.dir[i]) {
+		s.dir[i] = an.right(s.dir[i], (s.intn(i, 3)-1)*2)
+		tries := 0
+		for an.blocked(s.pos[i], s.dir[i]) {
+			s.dir[i] = an.right(s.dir[i], (s.intn(i, 3)-1)*2)
 			//a.dir = a.dir.Right(1)
-			i++
-			if i >= 64 {
-				a.pos.x = 1
-				a.pos.y = 1
+			tries++
+			if tries >= 64 {
+				s.pos[i].x = 1
+				s.pos[i].y = 1
 				return
 			}
 		}
 	}
 
-	a.pos = a.pos.PointAt(a.dir)
+	s.pos[i], _ = an.cell(an.step(s.pos[i], s.dir[i]))
+}
+
+// probes are what an ant senses along the five lines ahead of it.
+type probes struct {
+	lleft, left, straight, right, rright gridspot
+}
+
+// probeDirs returns the directions of the probes, in the order lleft, left,
+// straight, right, rright.
+func (s *antStore) probeDirs(an *AntScene, i int) [5]direction {
+	return [5]direction{an.left(s.dir[i], 2), an.left(s.dir[i], 1), s.dir[i], an.right(s.dir[i], 1), an.right(s.dir[i], 2)}
+}
+
+// sense probes the five lines ahead of the ant. The left, straight and right
+// probes include weighted values of their neighbours.
+func (s *antStore) sense(an *AntScene, i, sight int) probes {
+	// straight := a.SumOctant(an, a.dir, 50)
+	// left := a.SumOctant(an, a.dir.Left(1), 50)
+	// right := a.SumOctant(an, a.dir.Right(1), 50)
+
+	//const sight = 50
+	//const sight = 10
+	var pr probes
+	pr.straight = an.probe(s.pos[i], s.dir[i], sight)
+	pr.left = an.probe(s.pos[i], an.left(s.dir[i], 1), sight)
+	pr.lleft = an.probe(s.pos[i], an.left(s.dir[i], 2), sight)
+	pr.right = an.probe(s.pos[i], an.right(s.dir[i], 1), sight)
+	pr.rright = an.probe(s.pos[i], an.right(s.dir[i], 2), sight)
+
+	if pr.straight.FoodPher < 0 || pr.right.FoodPher < 0 || pr.left.FoodPher < 0 {
+		panic(fmt.Sprintf("Ant(%d,%d,%d): Less that zero: straight: %#v, left: %#v, right: %#v, lleft: %#v, rright: %#v",
+			s.pos[i].x, s.pos[i].y, s.dir[i], pr.straight, pr.left, pr.right, pr.lleft, pr.rright))
+	}
+
+	// Directions include weighted values of their left and right directions
+	pr.straight.FoodPher += pr.left.FoodPher/2 + pr.right.FoodPher/2
+	pr.straight.HomePher += pr.left.HomePher/2 + pr.right.HomePher/2
+	pr.left.FoodPher += pr.lleft.FoodPher/2 + pr.straight.FoodPher/2
+	pr.left.HomePher += pr.lleft.HomePher/2 + pr.straight.HomePher/2
+	pr.right.FoodPher += pr.rright.FoodPher/2 + pr.straight.FoodPher/2
+	pr.right.HomePher += pr.rright.HomePher/2 + pr.straight.HomePher/2
+	return pr
+}
+
+// steer decides how far right (or left, if negative) the ant turns given
+// what it sensed. If wander is set, the ant ignores pheromones.
+func (s *antStore) steer(an *AntScene, i int, gn *genome, pr probes, wander bool) int {
+	turn := 0
+	followingPher := false
+	if wander {
+		// Ignore the pheromones this time and just explore.
+	} else if s.food[i] > 0 { //|| s.life[i] < antlife/2 { // go home if we have food or we need food
+		// if rightPower > straightPower && rightPower > leftPower {
+		// 	a.dir = a.dir.Right(1)
+		// 	followingPher = true
+		// } else if leftPower > straightPower && leftPower > rightPower {
+		// 	a.dir = a.dir.Left(1)
+		// 	followingPher = true
+		// }
+		if pr.right.HomePher > pr.straight.HomePher && pr.right.HomePher > pr.left.HomePher {
+			turn++
+			followingPher = true
+		} else if pr.left.HomePher > pr.straight.HomePher && pr.left.HomePher > pr.right.HomePher {
+			turn--
+			followingPher = true
+		} else if pr.straight.HomePher > pr.left.HomePher && pr.straight.HomePher > pr.right.HomePher {
+			followingPher = true
+		}
+	} else {
+		if an.st.antisocial {
+			if pr.straight.Wall {
+				pr.straight.HomePher += pheromoneMax * gn.sight
+			}
+			if pr.left.Wall {
+				pr.left.HomePher += pheromoneMax * gn.sight
+			}
+			if pr.right.Wall {
+				pr.right.HomePher += pheromoneMax * gn.sight
+			}
+			straightPower := pr.straight.HomePher - (pr.straight.FoodPher * 2)
+			leftPower := pr.left.HomePher - (pr.left.FoodPher * 2)
+			rightPower := pr.right.HomePher - (pr.right.FoodPher * 2)
+
+			if rightPower < straightPower && rightPower < leftPower {
+				turn++
+				//followingPher = true
+			} else if leftPower < straightPower && leftPower < rightPower {
+				turn--
+				//followingPher = true
+			}
+		} else {
+			if pr.right.FoodPher > pr.straight.FoodPher && pr.right.FoodPher > pr.left.FoodPher {
+				turn++
+				followingPher = true
+			} else if pr.left.FoodPher > pr.straight.FoodPher && pr.left.FoodPher > pr.right.FoodPher {
+				turn--
+				followingPher = true
+			} else if pr.straight.FoodPher > pr.left.FoodPher && pr.straight.FoodPher > pr.right.FoodPher {
+				followingPher = true
+			}
+		}
+	}
+
+	if gn.followWalls {
+		if !followingPher {
+			if pr.lleft.Wall {
+				if pr.left.Wall {
+					if pr.straight.Wall {
+						turn++
+					}
+				} else {
+					turn--
+				}
+			}
+			if pr.rright.Wall {
+				if pr.right.Wall {
+					if pr.straight.Wall {
+						turn--
+					}
+				} else {
+					turn++
+				}
+			}
+		}
+	}
+	return turn
 }
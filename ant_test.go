@@ -38,3 +38,65 @@ func TestDirection(t *testing.T) {
 	}
 
 }
+
+func TestProbeDirs(t *testing.T) {
+	as := testScene(t, 40, 40)
+	as.ants.add(Ant{pos: point{20, 20}, dir: N})
+	want := [5]direction{W, NW, N, NE, E}
+	if got := as.ants.probeDirs(as, 0); got != want {
+		t.Errorf("Expected probes %v facing North, got %v", want, got)
+	}
+}
+
+func TestSense(t *testing.T) {
+	as := testScene(t, 40, 40)
+	as.field.Get(20, 15).FoodPher = 1000
+	as.ants.add(Ant{pos: point{20, 20}, dir: N})
+
+	pr := as.ants.sense(as, 0, 10)
+	if pr.straight.FoodPher != 1000 {
+		t.Errorf("Expected to sense 1000 food pheromone straight ahead, got %d", pr.straight.FoodPher)
+	}
+	// Left and right are weighted with half of what's straight ahead.
+	if pr.left.FoodPher != 500 || pr.right.FoodPher != 500 {
+		t.Errorf("Expected 500 on each side, got %d and %d", pr.left.FoodPher, pr.right.FoodPher)
+	}
+	if pr.lleft.FoodPher != 0 || pr.rright.FoodPher != 0 {
+		t.Errorf("Expected nothing far left or right, got %d and %d", pr.lleft.FoodPher, pr.rright.FoodPher)
+	}
+}
+
+func TestSteer(t *testing.T) {
+	as := testScene(t, 40, 40)
+	as.ants.add(Ant{pos: point{20, 20}, dir: N})
+	var gn genome
+
+	var pr probes
+	pr.left.FoodPher = 100
+	if turn := as.ants.steer(as, 0, &gn, pr, false); turn != -1 {
+		t.Errorf("Expected to turn left towards food pheromone, turned %d", turn)
+	}
+	if turn := as.ants.steer(as, 0, &gn, pr, true); turn != 0 {
+		t.Errorf("Expected a wandering ant to ignore pheromone, turned %d", turn)
+	}
+
+	as.ants.food[0] = 10
+	pr = probes{}
+	pr.right.HomePher = 100
+	pr.left.FoodPher = 500
+	if turn := as.ants.steer(as, 0, &gn, pr, false); turn != 1 {
+		t.Errorf("Expected an ant carrying food to turn right towards home pheromone, turned %d", turn)
+	}
+
+	as.ants.food[0] = 0
+	gn.followWalls = true
+	pr = probes{}
+	pr.lleft.Wall = true
+	if turn := as.ants.steer(as, 0, &gn, pr, false); turn != -1 {
+		t.Errorf("Expected a wall follower to turn left towards the wall, turned %d", turn)
+	}
+	pr.left.Wall, pr.straight.Wall = true, true
+	if turn := as.ants.steer(as, 0, &gn, pr, false); turn != 1 {
+		t.Errorf("Expected a wall follower to turn right away from a wall ahead, turned %d", turn)
+	}
+}
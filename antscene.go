@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"image/color"
 	"math"
+	"math/rand"
 	"os"
 	"runtime"
 	"sync"
@@ -31,15 +32,27 @@ type gridspot struct {
 
 const antTexSize = 5
 const pheromoneMax = 8191
-const pherShift = 5 //(2^13 = 8192), meaning 8192 is within 13 bits range, We want to shift that to 8 bits, so shift 5 out.
 const marker = 5000
 
 type AntScene struct {
 	st             *GameState
-	ants           []Ant
+	ants           antStore
 	field          *Field[gridspot]
-	textures       []*ebiten.Image
-	fullTextures   []*ebiten.Image
+	pher           pherTiles
+	heat           *heatmap      // The heatmap layer being recorded, if any
+	world          *ebiten.Image // The whole field and ants, before the view is cut out of it
+	view           point         // Top left of the part of the world on screen
+	mini           *minimap
+	trails         tracer
+	trips          tripStats
+	nestDist       *distField
+	distOverlay    *distOverlay
+	shader         *fieldShader // Created on first use by RenderShader
+	antAtlas       *ebiten.Image
+	antVerts       []ebiten.Vertex // Reused by drawAnts each frame
+	antIndices     []uint16
+	legend         [2]*ebiten.Image // Rebuilt by buildLegend when drawnColors changes
+	drawnColors    colorKey         // The colour settings renderbuf was last drawn with
 	pause          bool
 	mousePX        int
 	mousePY        int
@@ -47,10 +60,23 @@ type AntScene struct {
 	antWorkChan    chan gridwork
 	renderWorkChan chan renderwork
 	homelife       int64
+	frame          uint64
+	quiet          bool // Don't log the population every few ticks
+	screenshot     bool // Save the next drawn frame
+	clip           *clipRecorder
+	genePool       genePool
+	delivered      int64      // Total food delivered to the hive
+	rng            *rand.Rand // If set, used instead of the global source when spawning ants
+	nextID         uint64
+	inspect        *inspector
+	panelFont      font.Face
 
 	antwg            sync.WaitGroup
 	antworkerTrigger []chan struct{}
 
+	commitwg            sync.WaitGroup
+	commitworkerTrigger []chan struct{}
+
 	pherwg            sync.WaitGroup
 	pherworkerTrigger []chan struct{}
 }
@@ -70,8 +96,18 @@ type renderwork struct {
 
 var _ Scene[GameState] = &AntScene{}
 
+const gridFile = "ants.grid"
+
 func (as *AntScene) SaveGrid() error {
-	f, err := os.Create("ants.grid")
+	return as.SaveGridFile(gridFile)
+}
+
+func (as *AntScene) LoadGrid() error {
+	return as.LoadGridFile(gridFile)
+}
+
+func (as *AntScene) SaveGridFile(path string) error {
+	f, err := os.Create(path)
 	if err != nil {
 		return err
 	}
@@ -81,8 +117,8 @@ func (as *AntScene) SaveGrid() error {
 	return enc.Encode(as.field.vals)
 }
 
-func (as *AntScene) LoadGrid() error {
-	f, err := os.Open("ants.grid")
+func (as *AntScene) LoadGridFile(path string) error {
+	f, err := os.Open(path)
 	if err != nil {
 		return err
 	}
@@ -94,13 +130,45 @@ func (as *AntScene) LoadGrid() error {
 	if err != nil {
 		return err
 	}
+	if len(g) != len(as.field.vals) {
+		return fmt.Errorf("grid %s has %d cells, but the field has %d", path, len(g), len(as.field.vals))
+	}
 	as.field.vals = g
 	as.field.UpdateAll()
+	as.pher.markAll()
+	as.nestDist = nil
 	return nil
 }
 
+// panStep is how far the view moves per frame while panning.
+const panStep = 10
+
+// pan moves the view while the arrow keys are held.
+func (as *AntScene) pan(g *Game[GameState]) {
+	v := as.view
+	if ebiten.IsKeyPressed(ebiten.KeyLeft) {
+		v.x -= panStep
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyRight) {
+		v.x += panStep
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyUp) {
+		v.y -= panStep
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyDown) {
+		v.y += panStep
+	}
+	as.setView(v, g.width, g.height)
+}
+
 // func (as *AntScene) HandleEvent(g *Game[GameState], r *sdl.Renderer, e sdl.Event) error {
 func (as *AntScene) HandleInput(g *Game[GameState]) error {
+	// Shift and the arrow keys pan the view rather than doing what the arrow
+	// keys do on their own.
+	panning := ebiten.IsKeyPressed(ebiten.KeyShift)
+	if panning {
+		as.pan(g)
+	}
 	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
 		as.st.renderPher = !as.st.renderPher
 		fmt.Printf("RENDER PHEROMONES: %t\n", as.st.renderPher)
@@ -137,10 +205,11 @@ func (as *AntScene) HandleInput(g *Game[GameState]) error {
 				as.field.Update(x, y)
 			}
 		}
+		as.gridEdited()
 		as.relocateAnts()
 	} else if inpututil.IsKeyJustPressed(ebiten.KeyF) {
-		for y := 0; y < g.height; y++ {
-			for x := 0; x < g.width; x++ {
+		for y := 0; y < as.field.height; y++ {
+			for x := 0; x < as.field.width; x++ {
 				spot := as.field.Get(x, y)
 				*spot = gridspot{}
 				spot.Wall = true
@@ -156,6 +225,7 @@ func (as *AntScene) HandleInput(g *Game[GameState]) error {
 				as.field.Update(x, y)
 			}
 		}
+		as.gridEdited()
 	} else if inpututil.IsKeyJustPressed(ebiten.KeyX) {
 		as.st.parallel = !as.st.parallel
 		fmt.Printf("Parallel update: %t\n", as.st.parallel)
@@ -164,19 +234,59 @@ func (as *AntScene) HandleInput(g *Game[GameState]) error {
 	} else if inpututil.IsKeyJustPressed(ebiten.KeyW) {
 		as.st.followWalls = !as.st.followWalls
 		fmt.Printf("Wall Following: %t\n", as.st.followWalls)
-	} else if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
+	} else if !panning && inpututil.IsKeyJustPressed(ebiten.KeyUp) {
 		as.st.drawradius++
-	} else if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
+	} else if !panning && inpututil.IsKeyJustPressed(ebiten.KeyDown) {
 		as.st.drawradius--
-	} else if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
+	} else if !panning && inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
 		g.state.leftmode -= 1
 		if g.state.leftmode < 0 {
 			g.state.leftmode = end - 1
 		}
-	} else if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
+	} else if !panning && inpututil.IsKeyJustPressed(ebiten.KeyRight) {
 		g.state.leftmode = (g.state.leftmode + 1) % end
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyK) {
+		as.tagRandom(tagBatch)
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyU) {
+		as.untagAll()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyJ) {
+		err := as.saveTrails()
+		if err != nil {
+			fmt.Printf("Failed to save trails: %v\n", err)
+		}
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyN) {
+		as.st.minimap = !as.st.minimap
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+		as.st.distOverlay = !as.st.distOverlay
 	} else if inpututil.IsKeyJustPressed(ebiten.KeyZ) {
 		g.state.renderAnts = !g.state.renderAnts
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyD) {
+		as.st.renderSense = !as.st.renderSense
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyI) {
+		as.st.renderCell = !as.st.renderCell
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyE) {
+		as.st.evolve = !as.st.evolve
+		fmt.Printf("Evolution: %t\n", as.st.evolve)
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyT) {
+		err := as.saveGenomeStats()
+		if err != nil {
+			fmt.Printf("Failed to save genome stats: %v\n", err)
+		}
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyO) {
+		as.screenshot = true
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyV) {
+		if as.clip != nil {
+			as.clip.Stop()
+			as.clip = nil
+		} else {
+			c, err := newClipRecorder(as.st.captureDir, as.st.clipframes)
+			if err != nil {
+				fmt.Printf("Failed to start clip: %v\n", err)
+			} else {
+				fmt.Printf("Recording clip\n")
+				as.clip = c
+			}
+		}
 	}
 
 	distance := func(x0, y0, x1, y1 int) int {
@@ -188,66 +298,95 @@ func (as *AntScene) HandleInput(g *Game[GameState]) error {
 	//radius := 15
 	doSpot := func(x, y int, f func(x, y int, gs *gridspot)) {
 		for i := x - as.st.drawradius; i < x+as.st.drawradius; i++ {
-			if i < 0 || i >= g.width {
-				continue
-			}
 			for j := y - as.st.drawradius; j < y+as.st.drawradius; j++ {
-				if j < 0 || j >= g.height {
-					continue
-				}
 				//fmt.Printf("x0: %d, y0: %d, x1: %d, y1: %d, Dist: %d\n", i, j, x, y, distance(i, j, x, y))
 				if distance(i, j, x, y) > as.st.drawradius {
 					continue
 				}
-				spot := as.field.Get(int(i), int(j))
-				f(int(i), int(j), spot)
+				p, ok := as.cell(point{i, j})
+				if !ok {
+					continue
+				}
+				spot := as.field.Get(p.x, p.y)
+				f(p.x, p.y, spot)
 			}
 		}
 	}
 
-	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) && g.state.leftmode == wall {
+	if as.mini != nil && ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
 		mx, my := ebiten.CursorPosition()
+		if p, ok := as.mini.worldAt(g.width, g.height, mx, my); ok {
+			wx, wy := as.worldPixel(p)
+			as.setView(point{wx - g.width/2, wy - g.height/2}, g.width, g.height)
+			as.mousePX, as.mousePY = as.cursor()
+			return nil
+		}
+	}
+
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) && g.state.leftmode == wall {
+		mx, my := as.cursor()
+		walls := false
 		//if mx != as.mousePX || my != as.mousePY {
 		doLine(mx, my, as.mousePX, as.mousePY, func(cx, cy int) {
 			doSpot(cx, cy, func(x, y int, spot *gridspot) {
 				if spot.Home {
 					return
 				}
+				walls = walls || !spot.Wall
 				spot.Wall = true
 				//spot.Home = false
 				spot.Food = 0
 				as.field.Update(x, y)
 			})
 		})
+		if walls {
+			as.gridEdited()
+		}
 		//}
 	} else if ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight) ||
 		(ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) && g.state.leftmode == erase) {
-		mx, my := ebiten.CursorPosition()
+		mx, my := as.cursor()
+		walls := false
 		//if mx != as.mousePX || my != as.mousePY {
 		doLine(mx, my, as.mousePX, as.mousePY, func(cx, cy int) {
 			doSpot(cx, cy, func(x, y int, spot *gridspot) {
+				walls = walls || spot.Wall
 				spot.Wall = false
 				//spot.Home = false
 				spot.Food = 0
 				as.field.Update(x, y)
 			})
 		})
+		if walls {
+			as.gridEdited()
+		}
 		//}
+	} else if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && g.state.leftmode == inspect {
+		mx, my := as.cursor()
+		as.selectAnt(mx, my)
+	} else if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && g.state.leftmode == tag {
+		mx, my := as.cursor()
+		as.toggleTag(mx, my)
 	} else if ebiten.IsMouseButtonPressed(ebiten.MouseButtonMiddle) ||
 		(ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) && g.state.leftmode == food) {
-		mx, my := ebiten.CursorPosition()
+		mx, my := as.cursor()
+		walls := false
 		//if mx != as.mousePX || my != as.mousePY {
 		doLine(mx, my, as.mousePX, as.mousePY, func(cx, cy int) {
 			doSpot(cx, cy, func(x, y int, spot *gridspot) {
+				walls = walls || spot.Wall
 				spot.Wall = false
 				//spot.Home = false
 				spot.Food = as.st.foodcount
 				as.field.Update(x, y)
 			})
 		})
+		if walls {
+			as.gridEdited()
+		}
 		//}
 	}
-	mx, my := ebiten.CursorPosition()
+	mx, my := as.cursor()
 	as.mousePX = mx
 	as.mousePY = my
 	return nil
@@ -273,95 +412,166 @@ func (as *AntScene) renderGridspot(g *gridspot) uint32 {
 		return 0xFF3333FF
 	}
 	if as.st.renderPher {
-		var (
-			vg uint32
-			vr uint32
-		)
-		if as.st.renderGreen {
-			//vg = uint32(g.FoodPher / fooddivisor)
-			vg = uint32(g.FoodPher) >> pherShift
-			// if vg > 255 {
-			// 	fmt.Printf("FOOD > 255: %d\n", vg)
-			// 	vg = 255
-			// }
-			vg = (vg & 0xFF) << 8
-			//vg = vg << 16
-		}
-		if as.st.renderRed {
-			//vr = uint32(g.HomePher / homedivisor)
-			vr = uint32(g.HomePher) >> pherShift
-			// if vr > 255 {
-			// 	fmt.Printf("HOME > 255: %d\n", vg)
-			// 	vr = 255
-			// }
-			vr = (vr & 0xFF)
-			//vr = vr << 24
-		}
-		//return 0x000000FF | vg | vr
-		return 0xFF000000 | vg | vr
+		return as.pherColor(g)
 	} else {
 		return 0
 	}
 }
 
-func drawAntTextures(c color.Color) []*ebiten.Image {
-	textures := make([]*ebiten.Image, int(END))
-	setColor := func(im *ebiten.Image, c color.Color) func(x, y int) {
-		return func(x, y int) {
-			im.Set(x, y, c)
+var (
+	antColor     = color.RGBA{R: 0xc3, G: 0x5b, B: 0x31, A: 0xff}
+	antFullColor = color.RGBA{R: 0xc3, G: 0x5b, B: 0xff, A: 0xff}
+)
+
+// antTexLines is the line drawn into the ant texture for each direction, as
+// x0, y0, x1, y1.
+var antTexLines = [END][4]int{
+	N:  {antTexSize / 2, 0, antTexSize / 2, antTexSize},
+	NE: {0, antTexSize, antTexSize, 0},
+	E:  {0, antTexSize / 2, antTexSize, antTexSize / 2},
+	SE: {0, 0, antTexSize, antTexSize},
+	S:  {antTexSize / 2, 0, antTexSize / 2, antTexSize},
+	SW: {0, antTexSize, antTexSize, 0},
+	W:  {0, antTexSize / 2, antTexSize, antTexSize / 2},
+	NW: {0, 0, antTexSize, antTexSize},
+}
+
+// drawAntTexture calls f for every pixel of the ant texture for direction d.
+// Pixels of the line that fall outside the texture are skipped, the same as
+// ebiten.Image.Set does.
+func drawAntTexture(d direction, f func(x, y int)) {
+	l := antTexLines[d]
+	doLine(l[0], l[1], l[2], l[3], func(x, y int) {
+		if (point{x, y}).Within(0, 0, antTexSize, antTexSize) {
+			f(x, y)
+		}
+	})
+}
+
+// drawAntAtlas draws every ant texture into one image, so that all the ants
+// can be drawn with a few DrawTriangles calls. Each direction has a column,
+// with ants carrying nothing along the top row and ants carrying food along
+// the bottom.
+func drawAntAtlas() *ebiten.Image {
+	im := ebiten.NewImage(antTexSize*int(END), antTexSize*2)
+	for d := N; d < END; d++ {
+		for row, c := range []color.Color{antColor, antFullColor} {
+			x0, y0 := int(d)*antTexSize, row*antTexSize
+			drawAntTexture(d, func(x, y int) {
+				im.Set(x0+x, y0+y, c)
+			})
 		}
 	}
-	//N
-	textures[N] = ebiten.NewImage(antTexSize, antTexSize)
-	doLine(antTexSize/2, 0, antTexSize/2, antTexSize, setColor(textures[N], c))
+	return im
+}
 
-	//NE
-	textures[NE] = ebiten.NewImage(antTexSize, antTexSize)
-	doLine(0, antTexSize, antTexSize, 0, setColor(textures[NE], c))
+// antQuadsMax is the most ants one DrawTriangles call can take.
+const antQuadsMax = ebiten.MaxVerticesCount / 4
+
+// antVertices returns vs with a quad appended for every ant, and every copy of
+// it drawn across a wrapped edge. Each quad is four vertices: top left, top
+// right, bottom left, bottom right.
+func (as *AntScene) antVertices(vs []ebiten.Vertex) []ebiten.Vertex {
+	for a, p := range as.ants.pos {
+		sx := float32(int(as.ants.dir[a]) * antTexSize)
+		var sy float32
+		if as.ants.food[a] > 0 {
+			sy = antTexSize
+		}
+		as.wrapCopies(p, func(x, y int) {
+			wx, wy := as.worldPixel(point{x, y})
+			dx := float32(wx - antTexSize/2)
+			dy := float32(wy - antTexSize/2)
+			for _, c := range [4][2]float32{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+				vs = append(vs, ebiten.Vertex{
+					DstX: dx + c[0]*antTexSize, DstY: dy + c[1]*antTexSize,
+					SrcX: sx + c[0]*antTexSize, SrcY: sy + c[1]*antTexSize,
+					ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1,
+				})
+			}
+		})
+	}
+	return vs
+}
 
-	textures[E] = ebiten.NewImage(antTexSize, antTexSize)
-	doLine(0, antTexSize/2, antTexSize, antTexSize/2, setColor(textures[E], c))
+// antChunks calls f with each run of at most antQuadsMax quads in vs and the
+// indices to draw them with.
+func (as *AntScene) antChunks(vs []ebiten.Vertex, f func(vs []ebiten.Vertex, idx []uint16)) {
+	if as.antIndices == nil {
+		as.antIndices = make([]uint16, antQuadsMax*6)
+		for q := 0; q < antQuadsMax; q++ {
+			v := uint16(q * 4)
+			copy(as.antIndices[q*6:], []uint16{v, v + 1, v + 2, v + 1, v + 3, v + 2})
+		}
+	}
+	for len(vs) > 0 {
+		n := len(vs) / 4
+		if n > antQuadsMax {
+			n = antQuadsMax
+		}
+		f(vs[:n*4], as.antIndices[:n*6])
+		vs = vs[n*4:]
+	}
+}
 
-	textures[SE] = ebiten.NewImage(antTexSize, antTexSize)
-	doLine(0, 0, antTexSize, antTexSize, setColor(textures[SE], c))
+// drawAnts draws all the ants onto screen from the atlas.
+func (as *AntScene) drawAnts(screen *ebiten.Image) {
+	as.antVerts = as.antVertices(as.antVerts[:0])
+	as.antChunks(as.antVerts, func(vs []ebiten.Vertex, idx []uint16) {
+		screen.DrawTriangles(vs, idx, as.antAtlas, nil)
+	})
+}
 
-	textures[S] = ebiten.NewImage(antTexSize, antTexSize)
-	doLine(antTexSize/2, 0, antTexSize/2, antTexSize, setColor(textures[S], c))
+func (as *AntScene) Init(g *Game[GameState], st *GameState) error {
 
-	textures[SW] = ebiten.NewImage(antTexSize, antTexSize)
-	doLine(0, antTexSize, antTexSize, 0, setColor(textures[SW], c))
+	as.pause = true
+	err := as.initSim(st.width, st.height, st)
+	if err != nil {
+		return err
+	}
 
-	textures[W] = ebiten.NewImage(antTexSize, antTexSize)
-	doLine(0, antTexSize/2, antTexSize, antTexSize/2, setColor(textures[W], c))
+	as.antAtlas = drawAntAtlas()
 
-	textures[NW] = ebiten.NewImage(antTexSize, antTexSize)
-	doLine(0, 0, antTexSize, antTexSize, setColor(textures[NW], c))
+	// TTF
+	tt, err := opentype.Parse(fonts.MPlus1pRegular_ttf)
+	if err != nil {
+		return err
+	}
 
-	return textures
-}
+	const dpi = 72
+	mplusNormalFont, err = opentype.NewFace(tt, &opentype.FaceOptions{
+		Size:    antsceneFontSize,
+		DPI:     dpi,
+		Hinting: font.HintingVertical,
+	})
+	if err != nil {
+		return err
+	}
+	as.panelFont, err = opentype.NewFace(tt, &opentype.FaceOptions{
+		Size:    panelFontSize,
+		DPI:     dpi,
+		Hinting: font.HintingVertical,
+	})
+	if err != nil {
+		return err
+	}
 
-func (as *AntScene) Init(g *Game[GameState], st *GameState) error {
+	return nil
+}
 
-	as.pause = true
-	f, err := NewField[gridspot](g.width, g.height, as.renderGridspot)
+// initSim sets up the field, the hive and the update workers. It doesn't
+// touch any ebiten state, so it can be used without a window.
+func (as *AntScene) initSim(width, height int, st *GameState) error {
+	as.st = st
+	f, err := NewField[gridspot](width, height, as.renderGridspot)
 	if err != nil {
 		return err
 	}
 	as.field = f
+	as.pher = newPherTiles(width, height)
 
-	as.textures = make([]*ebiten.Image, int(END))
-	as.fullTextures = make([]*ebiten.Image, int(END))
-	//for i := N; i < END; i++ {
-	// as.textures[i] = ebiten.NewImage(antTexSize, antTexSize)
-	// as.textures[i].Fill(color.RGBA{R: 0xc3, G: 0x5b, B: 0x31, A: 0xff})
-	// as.fullTextures[i] = ebiten.NewImage(antTexSize, antTexSize)
-	// as.fullTextures[i].Fill(color.RGBA{R: 0xc3, G: 0x5b, B: 0xff, A: 0xff})
-	as.textures = drawAntTextures(color.RGBA{R: 0xc3, G: 0x5b, B: 0x31, A: 0xff})
-	as.fullTextures = drawAntTextures(color.RGBA{R: 0xc3, G: 0x5b, B: 0xff, A: 0xff})
-	//}
-
-	for y := 0; y < g.height; y++ {
-		for x := 0; x < g.width; x++ {
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
 			as.field.Get(x, y).Wall = true
 			as.field.Update(x, y)
 		}
@@ -375,68 +585,113 @@ func (as *AntScene) Init(g *Game[GameState], st *GameState) error {
 		}
 	}
 
-	for a := range as.ants {
-		as.ants[a].life = as.st.antlife
+	for a := range as.ants.life {
+		as.ants.life[a] = as.st.antlife
 	}
 	if as.homelife == 0 {
-		as.homelife = int64(len(as.ants)) * int64(as.st.antlife) * int64(as.st.spawnparam)
-	}
-
-	// TTF
-	tt, err := opentype.Parse(fonts.MPlus1pRegular_ttf)
-	if err != nil {
-		return err
+		as.homelife = int64(as.ants.len()) * int64(as.st.antlife) * int64(as.st.spawnparam)
 	}
 
-	const dpi = 72
-	mplusNormalFont, err = opentype.NewFace(tt, &opentype.FaceOptions{
-		Size:    antsceneFontSize,
-		DPI:     dpi,
-		Hinting: font.HintingVertical,
-	})
-
-	for i := 0; i < workers; i++ {
-		as.antworkerTrigger = append(as.antworkerTrigger, make(chan struct{}))
+	// Each worker gets its own channel, rather than indexing the slices,
+	// which are still being appended to.
+	n := workers
+	for i := 0; i < n; i++ {
+		trigger := make(chan struct{})
+		as.antworkerTrigger = append(as.antworkerTrigger, trigger)
 		go func(i int) {
-			for range as.antworkerTrigger[i] {
-				partsize := (len(as.ants) / workers) + 1
+			for range trigger {
+				partsize := (as.ants.len() / n) + 1
 				as.UpdateAntPartial((partsize * i), (partsize*i)+partsize)
 				as.antwg.Done()
 			}
 		}(i)
 
-		as.pherworkerTrigger = append(as.pherworkerTrigger, make(chan struct{}))
+		commitTrigger := make(chan struct{})
+		as.commitworkerTrigger = append(as.commitworkerTrigger, commitTrigger)
 		go func(i int) {
-			partsize := (as.field.height / workers) + 1
-			for range as.pherworkerTrigger[i] {
+			partsize := bandRows(as.field.height, n)
+			for range commitTrigger {
+				as.CommitAntPartial((partsize * i), (partsize*i)+partsize)
+				as.commitwg.Done()
+			}
+		}(i)
+
+		pherTrigger := make(chan struct{})
+		as.pherworkerTrigger = append(as.pherworkerTrigger, pherTrigger)
+		go func(i int) {
+			partsize := bandRows(as.field.height, n)
+			for range pherTrigger {
 				as.UpdatePherPartial((partsize * i), (partsize*i)+partsize)
 				as.pherwg.Done()
 			}
 		}(i)
 
 	}
-
 	return nil
 }
 
-func (as *AntScene) relocateAnts() {
-	for a := range as.ants {
-		as.ants[a].pos.x = 0
-		as.ants[a].pos.y = 0
+// intn returns a random number in [0,n) from the scene's source.
+func (as *AntScene) intn(n int) int {
+	if as.rng != nil {
+		return as.rng.Intn(n)
+	}
+	return rand.Intn(n)
+}
+
+func (as *AntScene) uint64() uint64 {
+	if as.rng != nil {
+		return as.rng.Uint64()
+	}
+	return rand.Uint64()
+}
+
+// float64 returns a random number in [0,1) from the scene's source.
+func (as *AntScene) float64() float64 {
+	if as.rng != nil {
+		return as.rng.Float64()
+	}
+	return rand.Float64()
+}
+
+// Close stops the update workers. The scene can't be stepped afterwards.
+func (as *AntScene) Close() {
+	for i := range as.antworkerTrigger {
+		close(as.antworkerTrigger[i])
+		close(as.commitworkerTrigger[i])
+		close(as.pherworkerTrigger[i])
 	}
 }
 
-var frame uint64
+func (as *AntScene) relocateAnts() {
+	for a := range as.ants.pos {
+		as.ants.pos[a].x = 0
+		as.ants.pos[a].y = 0
+	}
+}
 
 func (as *AntScene) UpdateAntPartial(start, end int) {
-	if start >= len(as.ants) {
+	if start >= as.ants.len() {
 		return
 	}
-	if end > len(as.ants) {
-		end = len(as.ants)
+	if end > as.ants.len() {
+		end = as.ants.len()
 	}
-	for a := range as.ants[start:end] {
-		as.ants[start+a].Update(as)
+	cg := as.st.casteGenomes()
+	for a := start; a < end; a++ {
+		as.ants.Update(as, a, &cg)
+	}
+}
+
+// CommitAntPartial commits the ants in rows [start, end) of the field, in
+// order. Ants in the same cell are always committed by the same call, so
+// splitting the field into bands of rows gives the same result as committing
+// every ant at once.
+func (as *AntScene) CommitAntPartial(start, end int) {
+	cg := as.st.casteGenomes()
+	for a, p := range as.ants.pos {
+		if p.y >= start && p.y < end && as.ants.life[a] > 0 {
+			as.ants.Commit(as, a, &cg)
+		}
 	}
 }
 
@@ -448,21 +703,12 @@ func (as *AntScene) UpdatePherPartial(start, end int) {
 		end = as.field.height
 	}
 
-	for y := start; y < end; y++ {
-		for x := 0; x < as.field.width; x++ {
-			update := false
-			spot := as.field.Get(x, y)
-			if spot.FoodPher > 0 {
-				spot.FoodPher -= (spot.FoodPher / as.st.fadedivisor) + 1
-				update = true
-			}
-			if spot.HomePher > 0 {
-				spot.HomePher -= (spot.HomePher / as.st.fadedivisor) + 1
-				update = true
-			}
-
-			if update && as.st.renderPher {
-				as.field.Update(x, y)
+	// start and end are on tile boundaries, except for the bottom of the
+	// field.
+	for ty := start / pherTile; ty*pherTile < end; ty++ {
+		for tx := 0; tx < as.pher.w; tx++ {
+			if as.pher.active[tx+ty*as.pher.w] {
+				as.decayTile(tx, ty)
 			}
 		}
 	}
@@ -477,22 +723,46 @@ func (as *AntScene) Update(g *Game[GameState], st *GameState) error {
 	if as.pause {
 		return nil
 	}
-	frame++
+	as.Step()
+	if as.inspect != nil {
+		as.inspect.observe(as)
+	}
+	return nil
+}
+
+// Step advances the simulation by one tick.
+func (as *AntScene) Step() {
+	st := as.st
+	as.frame++
+	as.syncHeatmap()
+	as.refreshNestDist()
 
 	n := as.st.maxants / as.st.antlife
 	if n == 0 {
 		n = 1
 	}
+	cg := st.casteGenomes()
 	for i := 0; i < n; i++ {
-		if len(as.ants) < st.maxants && as.homelife/(int64(st.antlife)*int64(st.spawnparam)) > int64(len(as.ants)) {
+		if as.ants.len() < st.maxants && as.homelife/(int64(st.antlife)*int64(st.spawnparam)) > int64(as.ants.len()) {
 			as.homelife -= int64(st.antlife)
-			as.ants = append(as.ants, Ant{life: as.st.antlife})
+			a := st.newAnt(st.randomCaste(as.intn))
+			// Genomes are only bred when evolving, so other runs don't
+			// spend the time or draw from the random source for them.
+			if st.evolve {
+				a.genome = as.genePool.child(st.baseGenome(), as.intn)
+			} else {
+				a.genome = cg[a.caste]
+			}
+			as.nextID++
+			a.id = as.nextID
+			a.rand = as.uint64()
+			as.ants.add(a)
 		}
 	}
 
-	if frame%10 == 0 {
+	if !as.quiet && as.frame%10 == 0 {
 		fmt.Printf("n: %d, homefood: %d, ants: %d, ratio: %d / %d \n",
-			n, as.homelife, len(as.ants), as.homelife/(int64(st.antlife)*int64(st.spawnparam)), len(as.ants))
+			n, as.homelife, as.ants.len(), as.homelife/(int64(st.antlife)*int64(st.spawnparam)), as.ants.len())
 	}
 
 	// partsize := (len(as.ants) / workers) + 1
@@ -500,32 +770,57 @@ func (as *AntScene) Update(g *Game[GameState], st *GameState) error {
 	// 	as.UpdateAntPartial((partsize * i), (partsize*i)+partsize)
 	// }
 
+	// Ants are moved, then act on the cells they moved to, in two phases so
+	// the parallel workers never touch the same cell at once.
 	if st.parallel {
-		as.antwg.Add(workers)
-		for i := 0; i < workers; i++ {
+		as.antwg.Add(len(as.antworkerTrigger))
+		for i := range as.antworkerTrigger {
 			as.antworkerTrigger[i] <- struct{}{}
 		}
 		as.antwg.Wait()
+		as.commitwg.Add(len(as.commitworkerTrigger))
+		for i := range as.commitworkerTrigger {
+			as.commitworkerTrigger[i] <- struct{}{}
+		}
+		as.commitwg.Wait()
 	} else {
-		as.UpdateAntPartial(0, len(as.ants))
+		as.UpdateAntPartial(0, as.ants.len())
+		as.CommitAntPartial(0, as.field.height)
 	}
 
-	var k int
-	for a := range as.ants {
-		if as.ants[a].life < 0 {
+	for a := 0; a < as.ants.len(); {
+		if as.ants.life[a] < 0 {
+			as.heat.record(layerDeaths, as.ants.pos[a], 0)
+			if as.ants.tagged[a] {
+				as.trails.end(as.ants.id[a])
+			}
+			as.trips.died(as.ants.trips[a])
+			as.ants.remove(a)
 			continue
 		}
-		as.ants[k] = as.ants[a]
-		k++
+		if d := as.ants.delivered[a]; d > 0 {
+			as.homelife += int64(d) * int64(st.foodlife)
+			as.delivered += int64(d)
+			as.trips.trip(as.ants.lastTrip[a])
+			as.trips.returned(as.nestDist.at(as.ants.pickupAt[a]), as.ants.homeSteps[a])
+			if st.evolve {
+				as.genePool.add(as.ants.genome[a])
+			}
+			as.ants.delivered[a] = 0
+		}
+		a++
+	}
+	if as.heat != nil {
+		as.heat.tick(as.frame, st.heatWindow)
 	}
-	as.ants = as.ants[:k]
+	as.recordTrails()
 
 	// newfoodPherMaxPresent = 1
 	// newhomePherMaxPresent = 1
 
 	if st.parallel {
-		as.pherwg.Add(workers)
-		for i := 0; i < workers; i++ {
+		as.pherwg.Add(len(as.pherworkerTrigger))
+		for i := range as.pherworkerTrigger {
 			as.pherworkerTrigger[i] <- struct{}{}
 		}
 		as.pherwg.Wait()
@@ -535,7 +830,6 @@ func (as *AntScene) Update(g *Game[GameState], st *GameState) error {
 
 	// foodPherMaxPresent = int(newfoodPherMaxPresent)
 	// homePherMaxPresent = int(newhomePherMaxPresent)
-	return nil
 }
 
 // Pheromone propagation from ants update loop.
@@ -616,33 +910,148 @@ func (as *AntScene) DrawUnder(g *Game[GameState], _ *GameState) bool {
 }
 
 // func (as *AntScene) Render(g *Game[GameState], r *sdl.Renderer, s *GameState) error {
+// sizedImage returns img, or a new image if img is nil or not w by h.
+func sizedImage(img *ebiten.Image, w, h int) *ebiten.Image {
+	if img == nil || img.Bounds().Dx() != w || img.Bounds().Dy() != h {
+		return ebiten.NewImage(w, h)
+	}
+	return img
+}
+
 func (as *AntScene) Draw(g *Game[GameState], st *GameState, screen *ebiten.Image) {
-	err := as.field.Render(screen)
+	if k := st.colorKey(); k != as.drawnColors {
+		as.drawnColors = k
+		as.field.UpdateAll()
+		as.buildLegend()
+	}
+
+	ww, wh := as.worldSize()
+	as.world = sizedImage(as.world, ww, wh)
+	world := as.world
+	world.Clear()
+
+	var err error
+	if st.gpuRender {
+		if err := as.RenderShader(world); err != nil {
+			// Fall back to the CPU renderer for good.
+			fmt.Printf("Failed to render with shader: %v\n", err)
+			st.gpuRender = false
+		}
+	}
+	if st.gpuRender {
+		// Already drawn.
+	} else if as.hexGrid() {
+		err = as.field.RenderHex(world)
+	} else {
+		err = as.field.Render(world)
+	}
 	if err != nil {
 		panic(err)
 	}
+	if as.heat != nil {
+		if err := as.heat.draw(as, world); err != nil {
+			panic(err)
+		}
+	}
+	if st.distOverlay {
+		as.refreshNestDist()
+		if as.distOverlay == nil {
+			o, err := newDistOverlay(as)
+			if err != nil {
+				fmt.Printf("Failed to create distance overlay: %v\n", err)
+				st.distOverlay = false
+			}
+			as.distOverlay = o
+		}
+		if as.distOverlay != nil {
+			if err := as.distOverlay.draw(as, world); err != nil {
+				panic(err)
+			}
+		}
+	} else {
+		as.distOverlay = nil
+	}
 
+	as.trails.draw(as, world, st.trailFade)
 	if st.renderAnts {
-		var dio ebiten.DrawImageOptions
-		for a := range as.ants {
-			if as.ants[a].food > 0 {
-				im := as.fullTextures[as.ants[a].dir]
-				dio.GeoM = ebiten.GeoM{}
-				dio.GeoM.Translate(float64(as.ants[a].pos.x-(antTexSize/2)), float64(as.ants[a].pos.y-(antTexSize/2)))
-				screen.DrawImage(im, &dio)
-			} else {
-				im := as.textures[as.ants[a].dir]
-				dio.GeoM = ebiten.GeoM{}
-				dio.GeoM.Translate(float64(as.ants[a].pos.x-(antTexSize/2)), float64(as.ants[a].pos.y-(antTexSize/2)))
-				screen.DrawImage(im, &dio)
-			}
+		as.drawAnts(world)
+	}
+	if st.renderSense {
+		as.drawSensing(world)
+	}
+	if as.inspect != nil {
+		as.inspect.drawWorld(as, world)
+	}
+
+	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+	as.setView(as.view, sw, sh)
+	var dio ebiten.DrawImageOptions
+	dio.GeoM.Translate(float64(-as.view.x), float64(-as.view.y))
+	screen.DrawImage(world, &dio)
+	if as.inspect != nil {
+		as.inspect.draw(as, screen)
+	}
+
+	if as.screenshot || as.clip != nil {
+		img := readScreen(screen)
+		if as.screenshot {
+			as.screenshot = false
+			go saveScreenshot(st.captureDir, img)
+		}
+		if as.clip != nil && !as.clip.Add(img) {
+			as.clip.Stop()
+			as.clip = nil
 		}
 	}
+
 	msg := fmt.Sprintf("FPS: %02.f, Ticks/Sec: %0.2f, Draw Radius: %d, Hive Life: %d, Ants: %d, Brush: %s",
-		ebiten.ActualFPS(), ebiten.ActualTPS(), st.drawradius, as.homelife, len(as.ants), as.st.leftmode)
-	start := antsceneFontSize * 2
-	text.Draw(screen, msg, mplusNormalFont, 10, start, color.White)
-	text.Draw(screen, "(M) menu", mplusNormalFont, 10, start+antsceneFontSpace, color.White)
+		ebiten.ActualFPS(), ebiten.ActualTPS(), st.drawradius, as.homelife, as.ants.len(), as.st.leftmode)
+	y := antsceneFontSize * 2
+	text.Draw(screen, msg, mplusNormalFont, 10, y, color.White)
+	y += antsceneFontSpace
+	text.Draw(screen, "(M) menu", mplusNormalFont, 10, y, color.White)
+	y += antsceneFontSpace
+	if as.trips.total > 0 {
+		text.Draw(screen, as.trips.summary(&as.ants), mplusNormalFont, 10, y, color.White)
+		y += antsceneFontSpace
+	}
+	if st.renderPher {
+		y = as.drawLegend(screen, y)
+	}
+	if st.minimap {
+		if as.mini == nil {
+			as.mini = newMinimap(as.field.width, as.field.height)
+		}
+		as.mini.draw(as, screen)
+	} else {
+		as.mini = nil
+	}
+	if as.heat != nil {
+		msg := fmt.Sprintf("Heatmap: %s, peak %.0f over the last %d ticks",
+			as.heat.layer, as.heat.max, as.frame-as.heat.start+uint64(st.heatWindow))
+		text.Draw(screen, msg, mplusNormalFont, 10, y, color.White)
+		y += antsceneFontSpace
+	}
+	if as.distOverlay != nil {
+		mx, my := as.cursor()
+		msg := fmt.Sprintf("Nest distance: farthest reachable cell %d steps", as.distOverlay.max)
+		if n := as.nestDist.at(point{mx, my}); n >= 0 {
+			msg += fmt.Sprintf(", cursor %d steps", n)
+		} else {
+			msg += ", cursor can't reach the hive"
+		}
+		text.Draw(screen, msg, mplusNormalFont, 10, y, color.White)
+		y += antsceneFontSpace
+	}
+	if st.evolve {
+		text.Draw(screen, "Mean genome: "+genomeSummary(as.ants.genome), mplusNormalFont, 10, y, color.White)
+		y += antsceneFontSpace
+	}
+	if st.renderCell {
+		mx, my := as.cursor()
+		text.Draw(screen, as.cellInfo(mx, my), mplusNormalFont, 10, y, color.White)
+		y += antsceneFontSpace
+	}
 	return
 }
 
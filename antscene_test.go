@@ -0,0 +1,73 @@
+package main
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// TestAntVertices checks that drawing the ants' quads from the atlas gives
+// the same picture as drawing each ant's texture, as Snapshot does. The atlas
+// is worked out from its layout in drawAntAtlas rather than read back.
+func TestAntVertices(t *testing.T) {
+	as := testScene(t, 20, 20)
+	as.st.wrap = true
+	for d := N; d < END; d++ {
+		as.ants.add(Ant{pos: point{2 + int(d)*2, 10}, dir: d, food: int(d) % 2})
+	}
+	as.ants.add(Ant{pos: point{0, 19}, dir: NE, food: 1})
+
+	as.st.renderAnts = true
+	want := as.Snapshot()
+	as.st.renderAnts = false
+	got := as.Snapshot()
+
+	vs := as.antVertices(nil)
+	if len(vs) != (as.ants.len()+3)*4 {
+		t.Fatalf("Expected a quad for each ant plus three for the copies of the corner ant, got %d vertices", len(vs))
+	}
+	for q := 0; q < len(vs); q += 4 {
+		tl, br := vs[q], vs[q+3]
+		if br.SrcX-tl.SrcX != antTexSize || br.SrcY-tl.SrcY != antTexSize ||
+			br.DstX-tl.DstX != antTexSize || br.DstY-tl.DstY != antTexSize {
+			t.Fatalf("Quad %d: expected %d pixels square, got source %v,%v-%v,%v and destination %v,%v-%v,%v",
+				q/4, antTexSize, tl.SrcX, tl.SrcY, br.SrcX, br.SrcY, tl.DstX, tl.DstY, br.DstX, br.DstY)
+		}
+		col, row := int(tl.SrcX)/antTexSize, int(tl.SrcY)/antTexSize
+		if int(tl.SrcX)%antTexSize != 0 || int(tl.SrcY)%antTexSize != 0 || col >= int(END) || row > 1 {
+			t.Fatalf("Quad %d: source %v,%v isn't a texture in the atlas", q/4, tl.SrcX, tl.SrcY)
+		}
+		c := []color.RGBA{antColor, antFullColor}[row]
+		drawAntTexture(direction(col), func(x, y int) {
+			if x, y := int(tl.DstX)+x, int(tl.DstY)+y; (point{x, y}).Within(0, 0, as.field.width, as.field.height) {
+				got.SetRGBA(x, y, c)
+			}
+		})
+	}
+	for i := range want.Pix {
+		if got.Pix[i] != want.Pix[i] {
+			x, y := (i/4)%as.field.width, (i/4)/as.field.width
+			t.Fatalf("Pixel (%d, %d) differs: drawn from the atlas %v, expected %v", x, y, got.At(x, y), want.At(x, y))
+		}
+	}
+}
+
+func TestAntChunks(t *testing.T) {
+	as := testScene(t, 10, 10)
+	quads := 0
+	as.antChunks(make([]ebiten.Vertex, 40000*4), func(vs []ebiten.Vertex, idx []uint16) {
+		if len(vs) > ebiten.MaxVerticesCount {
+			t.Errorf("Expected at most %d vertices in a call, got %d", ebiten.MaxVerticesCount, len(vs))
+		}
+		for _, i := range idx {
+			if int(i) >= len(vs) || int(i) >= ebiten.MaxVerticesCount {
+				t.Fatalf("Index %d is past the %d vertices in the call", i, len(vs))
+			}
+		}
+		quads += len(vs) / 4
+	})
+	if quads != 40000 {
+		t.Errorf("Expected all 40000 quads to be drawn, got %d", quads)
+	}
+}
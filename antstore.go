@@ -0,0 +1,129 @@
+package main
+
+// antStore holds the ants as a struct of arrays: each field of Ant has its own
+// slice, indexed by ant. Loops that only need a few fields, like picking out
+// the ants in a band of rows or drawing them, then only touch those slices.
+//
+// Ants are removed by moving the last ant into their place, so removal is
+// O(1) but doesn't keep the ants in order.
+type antStore struct {
+	pos    []point
+	dir    []direction
+	food   []int
+	marker []int
+	life   []int
+	caste  []caste
+	genome []genome
+	id     []uint64
+	age    []int
+
+	fx, fy  []float64
+	heading []float64
+
+	delivered []int
+	rand      []uint64
+	tagged    []bool
+
+	trips       []int
+	tripDist    []float64
+	lastTrip    []float64
+	sincePickup []int
+	pickupAt    []point
+	homeSteps   []int
+}
+
+func (s *antStore) len() int {
+	return len(s.pos)
+}
+
+func (s *antStore) add(a Ant) {
+	s.pos = append(s.pos, a.pos)
+	s.dir = append(s.dir, a.dir)
+	s.food = append(s.food, a.food)
+	s.marker = append(s.marker, a.marker)
+	s.life = append(s.life, a.life)
+	s.caste = append(s.caste, a.caste)
+	s.genome = append(s.genome, a.genome)
+	s.id = append(s.id, a.id)
+	s.age = append(s.age, a.age)
+	s.fx = append(s.fx, a.fx)
+	s.fy = append(s.fy, a.fy)
+	s.heading = append(s.heading, a.heading)
+	s.delivered = append(s.delivered, a.delivered)
+	s.rand = append(s.rand, a.rand)
+	s.tagged = append(s.tagged, a.tagged)
+	s.trips = append(s.trips, a.trips)
+	s.tripDist = append(s.tripDist, a.tripDist)
+	s.lastTrip = append(s.lastTrip, a.lastTrip)
+	s.sincePickup = append(s.sincePickup, a.sincePickup)
+	s.pickupAt = append(s.pickupAt, a.pickupAt)
+	s.homeSteps = append(s.homeSteps, a.homeSteps)
+}
+
+// get returns a copy of ant i.
+func (s *antStore) get(i int) Ant {
+	return Ant{
+		pos:         s.pos[i],
+		dir:         s.dir[i],
+		food:        s.food[i],
+		marker:      s.marker[i],
+		life:        s.life[i],
+		caste:       s.caste[i],
+		genome:      s.genome[i],
+		id:          s.id[i],
+		age:         s.age[i],
+		fx:          s.fx[i],
+		fy:          s.fy[i],
+		heading:     s.heading[i],
+		delivered:   s.delivered[i],
+		rand:        s.rand[i],
+		tagged:      s.tagged[i],
+		trips:       s.trips[i],
+		tripDist:    s.tripDist[i],
+		lastTrip:    s.lastTrip[i],
+		sincePickup: s.sincePickup[i],
+		pickupAt:    s.pickupAt[i],
+		homeSteps:   s.homeSteps[i],
+	}
+}
+
+// remove removes ant i, replacing it with the last ant.
+func (s *antStore) remove(i int) {
+	s.pos = swapRemove(s.pos, i)
+	s.dir = swapRemove(s.dir, i)
+	s.food = swapRemove(s.food, i)
+	s.marker = swapRemove(s.marker, i)
+	s.life = swapRemove(s.life, i)
+	s.caste = swapRemove(s.caste, i)
+	s.genome = swapRemove(s.genome, i)
+	s.id = swapRemove(s.id, i)
+	s.age = swapRemove(s.age, i)
+	s.fx = swapRemove(s.fx, i)
+	s.fy = swapRemove(s.fy, i)
+	s.heading = swapRemove(s.heading, i)
+	s.delivered = swapRemove(s.delivered, i)
+	s.rand = swapRemove(s.rand, i)
+	s.tagged = swapRemove(s.tagged, i)
+	s.trips = swapRemove(s.trips, i)
+	s.tripDist = swapRemove(s.tripDist, i)
+	s.lastTrip = swapRemove(s.lastTrip, i)
+	s.sincePickup = swapRemove(s.sincePickup, i)
+	s.pickupAt = swapRemove(s.pickupAt, i)
+	s.homeSteps = swapRemove(s.homeSteps, i)
+}
+
+func swapRemove[T any](s []T, i int) []T {
+	last := len(s) - 1
+	s[i] = s[last]
+	return s[:last]
+}
+
+// find returns the index of the ant with the given id.
+func (s *antStore) find(id uint64) (int, bool) {
+	for i := range s.id {
+		if s.id[i] == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
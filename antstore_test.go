@@ -0,0 +1,160 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestAntStoreRemove(t *testing.T) {
+	var s antStore
+	for id := uint64(1); id <= 3; id++ {
+		s.add(Ant{id: id, pos: point{int(id), 0}})
+	}
+	s.remove(0)
+	if s.len() != 2 {
+		t.Fatalf("Expected 2 ants after removing one, but have %d", s.len())
+	}
+	if a := s.get(0); a.id != 3 || a.pos != (point{3, 0}) {
+		t.Errorf("Expected the last ant to be moved into the removed ant's place, but got %+v", a)
+	}
+	if _, ok := s.find(1); ok {
+		t.Errorf("Removed ant can still be found")
+	}
+	if i, ok := s.find(2); !ok || i != 1 {
+		t.Errorf("Expected ant 2 at index 1, but got %d, %t", i, ok)
+	}
+}
+
+// benchScene returns an open field with n long lived ants that have had time
+// to spread out and lay some trails.
+func benchScene(b *testing.B, n int) *AntScene {
+	st := NewGameState(1280, 720)
+	st.renderPher = false
+	st.maxants = n
+	as, err := newHeadlessScene(&st, 0, "")
+	if err != nil {
+		b.Fatal(err)
+	}
+	as.rng = rand.New(rand.NewSource(1))
+	for i := range as.field.vals {
+		as.field.vals[i].Wall = false
+	}
+	for x := 400; x < 440; x++ {
+		for y := 250; y < 290; y++ {
+			as.field.Get(x, y).Food = 1000
+		}
+	}
+	for as.ants.len() < n {
+		a := st.newAnt(st.randomCaste(as.intn))
+		a.life = 1 << 30
+		a.rand = as.uint64()
+		a.pos = point{as.intn(100), as.intn(100)}
+		as.ants.add(a)
+	}
+	for i := 0; i < 200; i++ {
+		as.UpdateAntPartial(0, as.ants.len())
+		as.CommitAntPartial(0, as.field.height)
+	}
+	b.Cleanup(as.Close)
+	return as
+}
+
+func BenchmarkUpdateAntPartial(b *testing.B) {
+	as := benchScene(b, 40000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		as.UpdateAntPartial(0, as.ants.len())
+	}
+}
+
+func BenchmarkCommitAntPartial(b *testing.B) {
+	as := benchScene(b, 40000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		as.CommitAntPartial(0, as.field.height)
+	}
+}
+
+func BenchmarkStep(b *testing.B) {
+	as := benchScene(b, 40000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		as.Step()
+	}
+}
+
+// benchAnts copies the bench scene's ants into a []Ant, the way they were kept
+// before antStore, as a baseline for the store's benchmarks.
+func benchAnts(as *AntScene) []Ant {
+	ants := make([]Ant, as.ants.len())
+	for i := range ants {
+		ants[i] = as.ants.get(i)
+	}
+	return ants
+}
+
+// The band scans pick out the live ants in a band of rows, as each Commit
+// worker does. The count goes in sinkInt so the loop isn't optimised away.
+const benchBand = 32
+
+var sinkInt int
+
+func BenchmarkBandScanSlice(b *testing.B) {
+	ants := benchAnts(benchScene(b, 40000))
+	b.ResetTimer()
+	n := 0
+	for i := 0; i < b.N; i++ {
+		for a := range ants {
+			if ants[a].life > 0 && ants[a].pos.y >= benchBand && ants[a].pos.y < 2*benchBand {
+				n++
+			}
+		}
+	}
+	sinkInt = n
+}
+
+func BenchmarkBandScanStore(b *testing.B) {
+	as := benchScene(b, 40000)
+	b.ResetTimer()
+	n := 0
+	for i := 0; i < b.N; i++ {
+		for a, p := range as.ants.pos {
+			if p.y >= benchBand && p.y < 2*benchBand && as.ants.life[a] > 0 {
+				n++
+			}
+		}
+	}
+	sinkInt = n
+}
+
+// The sweeps drop dead ants after a tick. The slice is compacted by copying
+// every ant, while the store only reads each ant's life.
+func BenchmarkSweepSlice(b *testing.B) {
+	ants := benchAnts(benchScene(b, 40000))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		k := 0
+		for a := range ants {
+			if ants[a].life < 0 {
+				continue
+			}
+			ants[k] = ants[a]
+			k++
+		}
+		ants = ants[:k]
+	}
+}
+
+func BenchmarkSweepStore(b *testing.B) {
+	as := benchScene(b, 40000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for a := 0; a < as.ants.len(); {
+			if as.ants.life[a] < 0 {
+				as.ants.remove(a)
+				continue
+			}
+			a++
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ebiten runs at 60 frames per second, so each GIF frame is shown for about
+// 1/60th of a second, rounded to GIF's 100ths.
+const clipDelay = 2
+
+// clipRecorder collects screen frames and encodes them into a GIF in the
+// background, so recording doesn't stall the game loop. Frames that come in
+// faster than they can be encoded are dropped.
+type clipRecorder struct {
+	left    int // Frames left to record
+	dropped int // Read by the encoder once frames is closed
+	frames  chan *image.RGBA
+}
+
+func captureName(dir, ext string) (string, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ants-"+time.Now().Format("20060102-150405.000")+ext), nil
+}
+
+// readScreen copies the screen pixels out, making every pixel opaque since
+// uncovered parts of the screen are transparent.
+func readScreen(screen *ebiten.Image) *image.RGBA {
+	b := screen.Bounds()
+	img := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	screen.ReadPixels(img.Pix)
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 0xFF
+	}
+	return img
+}
+
+func saveScreenshot(dir string, img *image.RGBA) {
+	path, err := captureName(dir, ".png")
+	if err == nil {
+		err = writePNG(path, img)
+	}
+	if err != nil {
+		fmt.Printf("Failed to save screenshot: %v\n", err)
+		return
+	}
+	fmt.Printf("Saved screenshot %s\n", path)
+}
+
+func newClipRecorder(dir string, n int) (*clipRecorder, error) {
+	path, err := captureName(dir, ".gif")
+	if err != nil {
+		return nil, err
+	}
+	c := &clipRecorder{left: n, frames: make(chan *image.RGBA, 16)}
+	go func() {
+		var anim gif.GIF
+		for img := range c.frames {
+			anim.Image = append(anim.Image, toPaletted(img))
+			anim.Delay = append(anim.Delay, clipDelay)
+		}
+		if len(anim.Image) == 0 {
+			return
+		}
+		err := writeGIF(path, &anim)
+		if err != nil {
+			fmt.Printf("Failed to save clip: %v\n", err)
+			return
+		}
+		fmt.Printf("Saved clip %s (%d frames, %d dropped)\n", path, len(anim.Image), c.dropped)
+	}()
+	return c, nil
+}
+
+// Add records one frame, or drops it if the encoder is behind. It returns
+// false once the clip is full.
+func (c *clipRecorder) Add(img *image.RGBA) bool {
+	select {
+	case c.frames <- img:
+	default:
+		c.dropped++
+	}
+	c.left--
+	return c.left > 0
+}
+
+// Stop ends the recording and writes out whatever has been recorded.
+func (c *clipRecorder) Stop() {
+	close(c.frames)
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+func TestClipRecorderDrops(t *testing.T) {
+	// Nothing is encoding, so once the buffer is full frames must be
+	// dropped rather than block.
+	c := &clipRecorder{left: 5, frames: make(chan *image.RGBA, 2)}
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	for i := 0; i < 4; i++ {
+		if !c.Add(img) {
+			t.Fatalf("Expected the clip to want more frames after %d", i+1)
+		}
+	}
+	if c.Add(img) {
+		t.Errorf("Expected the clip to be full after 5 frames")
+	}
+	if len(c.frames) != 2 || c.dropped != 3 {
+		t.Errorf("Expected 2 frames kept and 3 dropped, got %d and %d", len(c.frames), c.dropped)
+	}
+}
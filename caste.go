@@ -0,0 +1,78 @@
+package main
+
+type caste int
+
+const (
+	forager caste = iota
+	scout
+	carrier
+	endCaste // Used for iteration
+)
+
+func (c caste) String() string {
+	switch c {
+	case forager:
+		return "Forager"
+	case scout:
+		return "Scout"
+	case carrier:
+		return "Carrier"
+	}
+	return "UNKNOWN"
+}
+
+type casteParams struct {
+	ratio  int // Relative share of newly spawned ants
+	sight  int // Sight distance, as a percentage of GameState.sight
+	life   int // Lifespan, as a percentage of GameState.antlife
+	carry  int // Amount of food picked up in one trip
+	wander int // Chance in 10 of ignoring pheromones when choosing a direction
+	slow   int // The ant moves once every slow ticks
+}
+
+// defaultCastes has every new ant a Forager, which behaves like an ant did
+// before castes. The other castes are there to be given a ratio.
+func defaultCastes() [endCaste]casteParams {
+	return [endCaste]casteParams{
+		forager: {ratio: 8, sight: 100, life: 100, carry: 10, wander: 0, slow: 1},
+		scout:   {ratio: 0, sight: 300, life: 100, carry: 5, wander: 5, slow: 1},
+		carrier: {ratio: 0, sight: 100, life: 150, carry: 30, wander: 0, slow: 2},
+	}
+}
+
+// randomCaste picks a caste for a new ant, weighted by the caste ratios. When
+// only one caste can be picked it's returned without drawing from intn, so
+// the default castes leave the random sequence alone.
+func (g *GameState) randomCaste(intn func(int) int) caste {
+	total, only := 0, forager
+	for c := range g.castes {
+		if r := g.castes[c].ratio; r > 0 {
+			total += r
+			only = caste(c)
+		}
+	}
+	if total <= 0 || total == g.castes[only].ratio {
+		return only
+	}
+	n := intn(total)
+	for c := range g.castes {
+		if n < g.castes[c].ratio {
+			return caste(c)
+		}
+		n -= g.castes[c].ratio
+	}
+	return forager
+}
+
+// newAnt creates a fresh ant of caste c.
+func (g *GameState) newAnt(c caste) Ant {
+	return Ant{life: g.antlife * g.castes[c].life / 100, caste: c}
+}
+
+func (g *GameState) casteSight(c caste) int {
+	v := g.sight * g.castes[c].sight / 100
+	if v < 1 {
+		v = 1
+	}
+	return v
+}
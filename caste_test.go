@@ -0,0 +1,49 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRandomCaste(t *testing.T) {
+	var st GameState
+	st.castes = defaultCastes()
+	for c := range st.castes {
+		st.castes[c].ratio = 0
+	}
+
+	if c := st.randomCaste(rand.Intn); c != forager {
+		t.Errorf("With no ratios set, new ants should be Foragers, but got %s", c)
+	}
+
+	st.castes[carrier].ratio = 3
+	for i := 0; i < 100; i++ {
+		if c := st.randomCaste(rand.Intn); c != carrier {
+			t.Fatalf("Only Carriers have a ratio, but got %s", c)
+		}
+	}
+}
+
+func TestDefaultCastes(t *testing.T) {
+	var st GameState
+	st.castes = defaultCastes()
+	drew := false
+	intn := func(n int) int {
+		drew = true
+		return rand.Intn(n)
+	}
+	if c := st.randomCaste(intn); c != forager || drew {
+		t.Errorf("Expected the default castes to give a Forager without drawing, got %s (drew %t)", c, drew)
+	}
+}
+
+func TestNewAntLife(t *testing.T) {
+	var st GameState
+	st.antlife = 1000
+	st.castes = defaultCastes()
+	st.castes[carrier].life = 150
+
+	if a := st.newAnt(carrier); a.life != 1500 || a.caste != carrier {
+		t.Errorf("Expected a Carrier with 1500 life, but got %s with %d", a.caste, a.life)
+	}
+}
@@ -0,0 +1,19 @@
+package main
+
+import "fmt"
+
+// cellInfo describes the gridspot at (x, y) and how many ants are on it.
+func (as *AntScene) cellInfo(x, y int) string {
+	if !(point{x, y}).Within(0, 0, as.field.width, as.field.height) {
+		return "Cell: off the field"
+	}
+	spot := as.field.Get(x, y)
+	n := 0
+	for _, p := range as.ants.pos {
+		if p.x == x && p.y == y {
+			n++
+		}
+	}
+	return fmt.Sprintf("Cell (%d, %d): FoodPher: %d, HomePher: %d, Food: %d, Home: %t, Wall: %t, Ants: %d",
+		x, y, spot.FoodPher, spot.HomePher, spot.Food, spot.Home, spot.Wall, n)
+}
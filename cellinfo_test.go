@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestCellInfo(t *testing.T) {
+	as := testScene(t, 20, 20)
+	as.field.Get(1, 1).Wall = true
+	food := as.field.Get(2, 1)
+	food.Food = 30
+	food.FoodPher = 5
+	hive := as.field.Get(3, 1)
+	hive.Home = true
+	hive.HomePher = 9
+	as.ants.add(Ant{pos: point{3, 1}})
+	as.ants.add(Ant{pos: point{3, 1}})
+
+	for _, c := range []struct {
+		x, y int
+		want string
+	}{
+		{1, 1, "Cell (1, 1): FoodPher: 0, HomePher: 0, Food: 0, Home: false, Wall: true, Ants: 0"},
+		{2, 1, "Cell (2, 1): FoodPher: 5, HomePher: 0, Food: 30, Home: false, Wall: false, Ants: 0"},
+		{3, 1, "Cell (3, 1): FoodPher: 0, HomePher: 9, Food: 0, Home: true, Wall: false, Ants: 2"},
+		{-1, 3, "Cell: off the field"},
+		{3, 20, "Cell: off the field"},
+	} {
+		if got := as.cellInfo(c.x, c.y); got != c.want {
+			t.Errorf("Expected %q, got %q", c.want, got)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// pherScale is how an amount of pheromone is mapped to a level from 0 to 255
+// before being coloured. Amounts past pheromoneMax are clamped.
+type pherScale int
+
+const (
+	scaleLinear pherScale = iota
+	scaleLog
+	endScale
+)
+
+func (s pherScale) String() string {
+	switch s {
+	case scaleLinear:
+		return "Linear"
+	case scaleLog:
+		return "Log"
+	}
+	return "UNKNOWN"
+}
+
+// pherLevels holds the level of every amount of pheromone for each scale.
+var pherLevels = func() (t [endScale][pheromoneMax + 1]uint8) {
+	for v := 0; v <= pheromoneMax; v++ {
+		t[scaleLinear][v] = uint8(v * 255 / pheromoneMax)
+		t[scaleLog][v] = uint8(math.Round(math.Log1p(float64(v)) / math.Log1p(pheromoneMax) * 255))
+	}
+	return t
+}()
+
+func (s pherScale) level(v int) uint8 {
+	return pherLevels[s][clampPher(v)]
+}
+
+// pherPalette colours pheromone levels. Red/Green and Blue/Orange colour each
+// pheromone separately and mix them; the others are single colour maps that
+// show whichever rendered pheromone is stronger.
+type pherPalette int
+
+const (
+	paletteRedGreen pherPalette = iota
+	paletteBlueOrange
+	paletteViridis
+	paletteMagma
+	endPalette
+)
+
+func (p pherPalette) String() string {
+	switch p {
+	case paletteRedGreen:
+		return "Red/Green"
+	case paletteBlueOrange:
+		return "Blue/Orange"
+	case paletteViridis:
+		return "Viridis"
+	case paletteMagma:
+		return "Magma"
+	}
+	return "UNKNOWN"
+}
+
+// mixed reports whether p colours each pheromone separately.
+func (p pherPalette) mixed() bool {
+	return p == paletteRedGreen || p == paletteBlueOrange
+}
+
+// Polynomial fits of matplotlib's viridis and magma maps, by Matt Zucker
+// (https://www.shadertoy.com/view/WlfXRN), as coefficients of t^0..t^6 for
+// each of r, g and b.
+var (
+	viridisCoeffs = [7][3]float64{
+		{0.2777273272234177, 0.005407344544966578, 0.3340998053353061},
+		{0.1050930431085774, 1.404613529898575, 1.384590162594685},
+		{-0.3308618287255563, 0.214847559468213, 0.09509516302823659},
+		{-4.634230498983486, -5.799100973351585, -19.33244095627987},
+		{6.228269936347081, 14.17993336680509, 56.69055260068105},
+		{4.776384997670288, -13.74514537774601, -65.35303263337234},
+		{-5.435455855934631, 4.645852612178535, 26.3124352495832},
+	}
+	magmaCoeffs = [7][3]float64{
+		{-0.002136485053939582, -0.000749655052795221, -0.005386127855323933},
+		{0.2516605407371642, 0.6775232436837668, 2.494026599312351},
+		{8.353717279216625, -3.577719514958484, 0.3144679030132573},
+		{-27.66873308576866, 14.26473078096533, -13.64921318813922},
+		{52.17613981234068, -27.94360607168351, 12.94416944238394},
+		{-50.76852536473588, 29.04658282127291, 4.23415299384598},
+		{18.65570506591883, -11.48977351997711, -5.601961508734096},
+	}
+)
+
+// polyColors evaluates the fit c at every level, packed like renderbuf.
+func polyColors(c *[7][3]float64) (t [256]uint32) {
+	for l := range t {
+		x := float64(l) / 255
+		var px uint32
+		for ch := 0; ch < 3; ch++ {
+			v := 0.0
+			for i := 6; i >= 0; i-- {
+				v = v*x + c[i][ch]
+			}
+			v = math.Max(0, math.Min(1, v))
+			px |= uint32(v*255+0.5) << (8 * ch)
+		}
+		t[l] = 0xFF000000 | px
+	}
+	return t
+}
+
+var paletteColors = [endPalette][256]uint32{
+	paletteViridis: polyColors(&viridisCoeffs),
+	paletteMagma:   polyColors(&magmaCoeffs),
+}
+
+// Colours of the food and home pheromone in the Blue/Orange palette, from
+// Okabe and Ito's colour-blind safe set.
+var (
+	blueOrangeFood = [3]uint32{0x00, 0x72, 0xB2}
+	blueOrangeHome = [3]uint32{0xE6, 0x9F, 0x00}
+)
+
+// color returns the colour of a cell with the food and home pheromone levels
+// given, packed like renderbuf.
+func (p pherPalette) color(food, home uint8) uint32 {
+	switch p {
+	case paletteRedGreen:
+		return 0xFF000000 | uint32(food)<<8 | uint32(home)
+	case paletteBlueOrange:
+		var px uint32
+		for ch := 0; ch < 3; ch++ {
+			v := (blueOrangeFood[ch]*uint32(food) + blueOrangeHome[ch]*uint32(home)) / 255
+			if v > 255 {
+				v = 255
+			}
+			px |= v << (8 * ch)
+		}
+		return 0xFF000000 | px
+	}
+	if home > food {
+		food = home
+	}
+	return paletteColors[p][food]
+}
+
+// pherColor colours a cell's pheromone with the current scale and palette.
+func (as *AntScene) pherColor(g *gridspot) uint32 {
+	var food, home uint8
+	if as.st.renderGreen {
+		food = as.st.pherScale.level(g.FoodPher)
+	}
+	if as.st.renderRed {
+		home = as.st.pherScale.level(g.HomePher)
+	}
+	return as.st.palette.color(food, home)
+}
+
+const (
+	legendWidth  = 256
+	legendHeight = 8
+)
+
+// legendBar is one bar of the legend.
+type legendBar struct {
+	name       string
+	food, home bool
+}
+
+// legendBars returns a bar for each rendered pheromone, or one for both with
+// a single colour map.
+func (st *GameState) legendBars() []legendBar {
+	var bars []legendBar
+	if st.palette.mixed() {
+		if st.renderGreen {
+			bars = append(bars, legendBar{"Food", true, false})
+		}
+		if st.renderRed {
+			bars = append(bars, legendBar{"Home", false, true})
+		}
+	} else if st.renderGreen || st.renderRed {
+		bars = append(bars, legendBar{"Pheromone", true, true})
+	}
+	return bars
+}
+
+// buildLegend draws the legend's bars from none on the left to pheromoneMax
+// on the right. They only change with the colour settings, so Draw calls it
+// when colorKey does.
+func (as *AntScene) buildLegend() {
+	buf := make([]uint32, legendWidth*legendHeight)
+	for i, b := range as.st.legendBars() {
+		for x := 0; x < legendWidth; x++ {
+			l := as.st.pherScale.level(x * pheromoneMax / (legendWidth - 1))
+			var food, home uint8
+			if b.food {
+				food = l
+			}
+			if b.home {
+				home = l
+			}
+			c := as.st.palette.color(food, home)
+			for row := 0; row < legendHeight; row++ {
+				buf[row*legendWidth+x] = c
+			}
+		}
+		if as.legend[i] == nil {
+			as.legend[i] = ebiten.NewImage(legendWidth, legendHeight)
+		}
+		as.legend[i].WritePixels(pixelBytes(buf))
+	}
+}
+
+// drawLegend draws the legend built by buildLegend and returns the y below it.
+func (as *AntScene) drawLegend(screen *ebiten.Image, y int) int {
+	for i, b := range as.st.legendBars() {
+		if as.legend[i] == nil {
+			as.buildLegend()
+		}
+		text.Draw(screen, b.name+" 0", mplusNormalFont, 10, y, color.White)
+		var dio ebiten.DrawImageOptions
+		dio.GeoM.Translate(150, float64(y-legendHeight))
+		screen.DrawImage(as.legend[i], &dio)
+		text.Draw(screen, fmt.Sprint(pheromoneMax), mplusNormalFont, 150+legendWidth+10, y, color.White)
+		y += antsceneFontSpace
+	}
+	return y
+}
+
+// colorKey is everything renderGridspot's colours depend on besides the cell.
+type colorKey struct {
+	scale      pherScale
+	palette    pherPalette
+	green, red bool
+}
+
+func (st *GameState) colorKey() colorKey {
+	return colorKey{st.pherScale, st.palette, st.renderGreen, st.renderRed}
+}
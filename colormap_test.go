@@ -0,0 +1,74 @@
+package main
+
+import "testing"
+
+func TestPherScaleClamps(t *testing.T) {
+	for s := scaleLinear; s < endScale; s++ {
+		if l := s.level(-10); l != 0 {
+			t.Errorf("%s: negative pheromone should be level 0, got %d", s, l)
+		}
+		if l := s.level(pheromoneMax); l != 255 {
+			t.Errorf("%s: pheromoneMax should be level 255, got %d", s, l)
+		}
+		if l := s.level(pheromoneMax + 500); l != 255 {
+			t.Errorf("%s: pheromone past the maximum should clamp to 255 rather than wrap, got %d", s, l)
+		}
+		prev := uint8(0)
+		for v := 0; v <= pheromoneMax; v++ {
+			l := s.level(v)
+			if l < prev {
+				t.Fatalf("%s: level dropped from %d to %d at %d", s, prev, l, v)
+			}
+			prev = l
+		}
+	}
+	if lin, log := scaleLinear.level(50), scaleLog.level(50); log <= lin {
+		t.Errorf("The log scale should show small amounts brighter than linear, got %d vs %d", log, lin)
+	}
+}
+
+func TestPaletteColors(t *testing.T) {
+	for _, c := range []struct {
+		p    pherPalette
+		l    uint8
+		want uint32
+	}{
+		// Ends of matplotlib's maps, allowing for the fits.
+		{paletteViridis, 0, 0xFF540144},
+		{paletteViridis, 255, 0xFF25E7FD},
+		{paletteMagma, 0, 0xFF040000},
+		{paletteMagma, 255, 0xFFBFFDFC},
+	} {
+		got := c.p.color(c.l, 0)
+		for ch := 0; ch < 32; ch += 8 {
+			d := int(got>>ch&0xFF) - int(c.want>>ch&0xFF)
+			if d < -6 || d > 6 {
+				t.Errorf("%s at %d: got %08X, want about %08X", c.p, c.l, got, c.want)
+				break
+			}
+		}
+	}
+	if got := paletteRedGreen.color(200, 100); got != 0xFF00C864 {
+		t.Errorf("Red/Green should put food in green and home in red, got %08X", got)
+	}
+	if paletteViridis.color(10, 200) != paletteViridis.color(200, 0) {
+		t.Errorf("Single colour maps should show the stronger pheromone")
+	}
+}
+
+func TestLegendBars(t *testing.T) {
+	st := testState()
+	st.renderGreen, st.renderRed = true, true
+	st.palette = paletteMagma
+	if bars := st.legendBars(); len(bars) != 1 || !bars[0].food || !bars[0].home {
+		t.Errorf("Expected one bar for both pheromones with a single colour map, got %+v", bars)
+	}
+	st.palette = paletteRedGreen
+	if bars := st.legendBars(); len(bars) != 2 || bars[0].name != "Food" || bars[1].name != "Home" {
+		t.Errorf("Expected a food and a home bar with a mixed palette, got %+v", bars)
+	}
+	st.renderGreen = false
+	if bars := st.legendBars(); len(bars) != 1 || bars[0].name != "Home" {
+		t.Errorf("Expected only the home bar when food isn't rendered, got %+v", bars)
+	}
+}
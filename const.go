@@ -6,3 +6,5 @@ const antsceneFontSize = 22
 const antsceneFontSpace = 28
 const optsceneFontSize = 15
 const optsceneFontSpace = 20
+const panelFontSize = 14
+const panelFontSpace = 18
@@ -4,3 +4,5 @@ const antsceneFontSize = 15
 const antsceneFontSpace = 16
 const optsceneFontSize = 15
 const optsceneFontSpace = 16
+const panelFontSize = 12
+const panelFontSpace = 14
@@ -0,0 +1,63 @@
+package main
+
+import "math"
+
+// Headings are in radians, clockwise from north, matching direction.
+
+func dirAngle(d direction) float64 {
+	return float64(d) * math.Pi / 4
+}
+
+// angleDir returns the direction closest to heading h.
+func angleDir(h float64) direction {
+	d := direction(math.Round(h/(math.Pi/4))) % END
+	if d < 0 {
+		d += END
+	}
+	return d
+}
+
+func (s *antStore) turnAround(an *AntScene, i int) {
+	s.dir[i] = an.right(s.dir[i], an.dirCount()/2)
+	s.heading[i] += math.Pi
+}
+
+// glide moves the ant along its heading in the continuous movement model.
+// If the way is blocked, it bounces off in a random direction away from the
+// obstacle.
+func (s *antStore) glide(an *AntScene, i int) {
+	if int(math.Floor(s.fx[i])) != s.pos[i].x || int(math.Floor(s.fy[i])) != s.pos[i].y {
+		// The ant was moved some other way, or has only just switched to
+		// continuous movement. Start it from the middle of its cell.
+		s.fx[i] = float64(s.pos[i].x) + 0.5
+		s.fy[i] = float64(s.pos[i].y) + 0.5
+		s.heading[i] = dirAngle(s.dir[i])
+	}
+	speed := float64(an.st.speed) / 100
+	for tries := 0; tries < 64; tries++ {
+		nx := s.fx[i] + math.Sin(s.heading[i])*speed
+		ny := s.fy[i] - math.Cos(s.heading[i])*speed
+		p, ok := an.cell(point{int(math.Floor(nx)), int(math.Floor(ny))})
+		if ok && !an.field.Get(p.x, p.y).Wall {
+			// Keep the position in the same cell as p, so it stays on the
+			// field if the world wraps.
+			nx += float64(p.x) - math.Floor(nx)
+			ny += float64(p.y) - math.Floor(ny)
+			s.fx[i], s.fy[i], s.pos[i] = nx, ny, p
+			s.heading[i] = math.Mod(s.heading[i], 2*math.Pi)
+			s.dir[i] = angleDir(s.heading[i])
+			return
+		}
+		s.heading[i] += math.Pi/2 + s.float64(i)*math.Pi
+	}
+	s.pos[i].x = 1
+	s.pos[i].y = 1
+}
+
+// headingOf returns the ant's heading, in the continuous model's radians.
+func (s *antStore) headingOf(as *AntScene, i int) float64 {
+	if as.st.continuous {
+		return s.heading[i]
+	}
+	return dirAngle(s.dir[i])
+}
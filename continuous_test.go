@@ -0,0 +1,46 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAngleDir(t *testing.T) {
+	for d := N; d < END; d++ {
+		if d2 := angleDir(dirAngle(d)); d2 != d {
+			t.Errorf("%s should round trip through its angle, but came back as %s", d, d2)
+		}
+		if d2 := angleDir(dirAngle(d) + 2*math.Pi + 0.3); d2 != d {
+			t.Errorf("%s plus a bit more than a full turn should still be %s, but is %s", d, d, d2)
+		}
+	}
+	if d := angleDir(-math.Pi / 4); d != NW {
+		t.Errorf("-45° should be NW, but is %s", d)
+	}
+}
+
+func TestGlide(t *testing.T) {
+	as := testScene(t, 40, 40)
+	as.st.speed = 50
+	s := &as.ants
+	s.add(Ant{pos: point{20, 20}, dir: E})
+
+	s.glide(as, 0)
+	if s.fx[0] != 21 || s.fy[0] != 20.5 || s.pos[0] != (point{21, 20}) {
+		t.Errorf("Expected the ant to glide half a cell east to (21, 20.5), but it's at (%f, %f) in %v", s.fx[0], s.fy[0], s.pos[0])
+	}
+	s.glide(as, 0)
+	if s.pos[0] != (point{21, 20}) {
+		t.Errorf("Expected the ant to still be in cell (21, 20), but it's in %v", s.pos[0])
+	}
+
+	for y := 0; y < 40; y++ {
+		as.field.Get(23, y).Wall = true
+	}
+	for i := 0; i < 100; i++ {
+		s.glide(as, 0)
+		if as.field.Get(s.pos[0].x, s.pos[0].y).Wall {
+			t.Fatalf("Ant glided into a wall at %v", s.pos[0])
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"reflect"
 	"unsafe"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -14,6 +13,14 @@ type Field[T any] struct {
 
 	width, height int
 
+	// Tiles with cells changed by Update since the field shader last packed
+	// them.
+	changed pherTiles
+
+	// Used by RenderHex.
+	hexIdx []int32
+	hexbuf []uint32
+
 	//tex *sdl.Texture
 }
 
@@ -24,6 +31,7 @@ func NewField[T any](width, height int, toColor func(*T) uint32) (*Field[T], err
 		valToColor: toColor,
 		width:      width,
 		height:     height,
+		changed:    newPherTiles(width, height),
 	}
 	return f, nil
 }
@@ -39,20 +47,25 @@ func (f *Field[T]) Get(x, y int) *T {
 
 func (f *Field[T]) Update(x, y int) {
 	f.renderbuf[x+y*f.width] = f.valToColor(&f.vals[x+y*f.width])
+	f.changed.mark(x, y)
 }
 
 func (f *Field[T]) UpdateAll() {
 	for i := range f.vals {
 		f.renderbuf[i] = f.valToColor(&f.vals[i])
 	}
+	f.changed.markAll()
 }
 
 func (f *Field[T]) Render(r *ebiten.Image) error {
-	var bbs []byte
-	sliceHeader := (*reflect.SliceHeader)(unsafe.Pointer(&bbs))
-	sliceHeader.Cap = int(len(f.renderbuf) * 4)
-	sliceHeader.Len = int(len(f.renderbuf) * 4)
-	sliceHeader.Data = uintptr(unsafe.Pointer(&f.renderbuf[0]))
-	r.ReplacePixels(bbs)
+	r.WritePixels(pixelBytes(f.renderbuf))
 	return nil
 }
+
+// pixelBytes returns buf as bytes, without copying.
+func pixelBytes(buf []uint32) []byte {
+	if len(buf) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&buf[0])), len(buf)*4)
+}
@@ -0,0 +1,18 @@
+package main
+
+import "testing"
+
+func TestPixelBytes(t *testing.T) {
+	if b := pixelBytes(nil); len(b) != 0 {
+		t.Errorf("Expected no bytes for an empty buffer, got %d", len(b))
+	}
+	buf := []uint32{0xFF3333FF, 0x01020304}
+	b := pixelBytes(buf)
+	if len(b) != 8 || b[0] != 0xFF || b[1] != 0x33 || b[3] != 0xFF || b[4] != 0x04 {
+		t.Errorf("Expected the little endian bytes of %08x, got %v", buf, b)
+	}
+	buf[1] = 0
+	if b[4] != 0 {
+		t.Errorf("Expected the bytes to share the buffer rather than copy it")
+	}
+}
@@ -6,6 +6,8 @@ const (
 	wall clickmode = iota
 	food
 	erase
+	inspect
+	tag
 	end
 )
 
@@ -17,6 +19,10 @@ func (m clickmode) String() string {
 		return "Food"
 	case erase:
 		return "Erase"
+	case inspect:
+		return "Inspect"
+	case tag:
+		return "Tag"
 	default:
 		return "Error"
 	}
@@ -29,6 +35,17 @@ type GameState struct {
 	renderGreen bool
 	renderRed   bool
 	renderAnts  bool
+	renderCell  bool
+	renderSense bool
+	pherScale   pherScale
+	palette     pherPalette
+	heatmap     heatLayer
+	heatWindow  int // Ticks each window of the heatmap covers
+	minimap     bool
+	distOverlay bool // Show each cell's distance to the hive
+	trailFade   int  // Ticks a traced trail takes to fade out
+	gpuRender   bool // Colour the field with a shader rather than on the CPU
+	threshold   int  // Pheromone below this isn't drawn by the shader
 	parallel    bool
 	followWalls bool
 	antisocial  bool
@@ -40,5 +57,15 @@ type GameState struct {
 	drawradius  int //Radius of the cursor paintbrush
 	fadedivisor int // pheromone -= pheromone / fadedivisor // bigger number, slower fade
 	sight       int
+	captureDir  string // Directory screenshots and clips are written to
+	clipframes  int    // Number of frames recorded in a clip
+	castes      [endCaste]casteParams
+	evolve      bool // Ants use their own inherited genomes rather than the parameters above
+	sensing     senseModel
+	saturate    int  // If positive, the most pheromone an ant senses from any one cell
+	continuous  bool // Ants move with float positions and headings rather than from cell to cell
+	speed       int  // Hundredths of a cell moved per tick in continuous movement
+	wrap        bool // The world is a torus, with each edge joined to the opposite one
+	hex         bool // Ants move on a hexagonal grid with six directions
 	leftmode    clickmode
 }
@@ -22,5 +22,11 @@ func NewGameState(width, height int) GameState {
 	g.drawradius = 20
 	g.fadedivisor = 700
 	g.sight = 10
+	g.captureDir = "captures"
+	g.clipframes = 300
+	g.castes = defaultCastes()
+	g.speed = 100
+	g.heatWindow = 1000
+	g.trailFade = 2000
 	return g
 }
@@ -15,5 +15,11 @@ func NewGameState(width, height int) GameState {
 	g.maxants = 1000
 	g.drawradius = 20
 	g.fadedivisor = 500
+	g.captureDir = "captures"
+	g.clipframes = 120
+	g.castes = defaultCastes()
+	g.speed = 100
+	g.heatWindow = 1000
+	g.trailFade = 2000
 	return g
 }
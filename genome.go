@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+)
+
+// genome holds the heritable behaviour parameters of an ant. They're only
+// used in evolve mode; otherwise ants follow the GameState parameters.
+type genome struct {
+	sight       int  // Sight distance
+	senseOdds   int  // Chance in 100 of sensing pheromones each tick
+	turnOdds    int  // Chance in 100 of taking a random turn after sensing
+	marker      int  // Marker strength laid down after leaving home or food
+	fade        int  // Fade divisor for the ant's own marker. Bigger number, slower fade
+	followWalls bool // Whether the ant follows walls when it isn't following pheromones
+}
+
+type gene struct {
+	name     string
+	min, max int
+	get      func(*genome) int
+	set      func(*genome, int)
+}
+
+var genes = []gene{
+	{"sight", 1, 100, func(g *genome) int { return g.sight }, func(g *genome, v int) { g.sight = v }},
+	{"sense", 1, 100, func(g *genome) int { return g.senseOdds }, func(g *genome, v int) { g.senseOdds = v }},
+	{"turn", 0, 100, func(g *genome) int { return g.turnOdds }, func(g *genome, v int) { g.turnOdds = v }},
+	{"marker", 100, pheromoneMax, func(g *genome) int { return g.marker }, func(g *genome, v int) { g.marker = v }},
+	{"fade", 1, 10000, func(g *genome) int { return g.fade }, func(g *genome, v int) { g.fade = v }},
+	{"walls", 0, 1, func(g *genome) int {
+		if g.followWalls {
+			return 1
+		}
+		return 0
+	}, func(g *genome, v int) { g.followWalls = v != 0 }},
+}
+
+// baseGenome is the genome equivalent to the GameState parameters.
+func (st *GameState) baseGenome() genome {
+	return genome{
+		sight:       st.sight,
+		senseOdds:   10,
+		turnOdds:    20,
+		marker:      marker,
+		fade:        st.fadedivisor,
+		followWalls: st.followWalls,
+	}
+}
+
+// casteGenomes returns the genome each caste uses when not evolving. It's
+// worked out once for each batch of ants rather than for every ant.
+func (st *GameState) casteGenomes() [endCaste]genome {
+	var cg [endCaste]genome
+	for c := range cg {
+		cg[c] = st.baseGenome()
+		cg[c].sight = st.casteSight(caste(c))
+	}
+	return cg
+}
+
+// genes returns the behaviour parameters ant i should use this tick.
+func (s *antStore) genes(an *AntScene, i int, cg *[endCaste]genome) genome {
+	if an.st.evolve {
+		return s.genome[i]
+	}
+	return cg[s.caste[i]]
+}
+
+func (g genome) mutate(intn func(int) int) genome {
+	for i := range genes {
+		if intn(4) != 0 {
+			continue
+		}
+		v := genes[i].get(&g)
+		step := v/10 + 1
+		v += intn(2*step+1) - step
+		if v < genes[i].min {
+			v = genes[i].min
+		}
+		if v > genes[i].max {
+			v = genes[i].max
+		}
+		genes[i].set(&g, v)
+	}
+	return g
+}
+
+const genePoolSize = 256
+
+// genePool remembers the genomes of ants that recently delivered food to the
+// hive. New ants are bred from it, so genomes that deliver more food spread.
+type genePool struct {
+	pool []genome
+	next int
+}
+
+func (p *genePool) add(g genome) {
+	if len(p.pool) < genePoolSize {
+		p.pool = append(p.pool, g)
+		return
+	}
+	p.pool[p.next] = g
+	p.next = (p.next + 1) % genePoolSize
+}
+
+// child returns a mutated copy of a random genome from the pool, or of base
+// if nothing has been delivered yet.
+func (p *genePool) child(base genome, intn func(int) int) genome {
+	if len(p.pool) == 0 {
+		return base.mutate(intn)
+	}
+	return p.pool[intn(len(p.pool))].mutate(intn)
+}
+
+type geneStat struct {
+	mean, stddev float64
+	min, max     int
+}
+
+// genomeStats summarises each gene over the living ants.
+func genomeStats(gs []genome) []geneStat {
+	stats := make([]geneStat, len(genes))
+	if len(gs) == 0 {
+		return stats
+	}
+	for i := range genes {
+		var sum, sumsq float64
+		s := &stats[i]
+		s.min = math.MaxInt
+		s.max = math.MinInt
+		for a := range gs {
+			v := genes[i].get(&gs[a])
+			sum += float64(v)
+			sumsq += float64(v) * float64(v)
+			if v < s.min {
+				s.min = v
+			}
+			if v > s.max {
+				s.max = v
+			}
+		}
+		n := float64(len(gs))
+		s.mean = sum / n
+		s.stddev = math.Sqrt(math.Max(0, sumsq/n-s.mean*s.mean))
+	}
+	return stats
+}
+
+func genomeCSVHeader() []string {
+	h := []string{"tick", "ants"}
+	for i := range genes {
+		n := genes[i].name
+		h = append(h, n+"_mean", n+"_stddev", n+"_min", n+"_max")
+	}
+	return h
+}
+
+func genomeCSVRecord(tick uint64, gs []genome) []string {
+	r := []string{strconv.FormatUint(tick, 10), strconv.Itoa(len(gs))}
+	for _, s := range genomeStats(gs) {
+		r = append(r,
+			strconv.FormatFloat(s.mean, 'f', 3, 64),
+			strconv.FormatFloat(s.stddev, 'f', 3, 64),
+			strconv.Itoa(s.min),
+			strconv.Itoa(s.max))
+	}
+	return r
+}
+
+// WriteGenomeStats writes the current genome statistics as CSV.
+func (as *AntScene) WriteGenomeStats(w io.Writer) error {
+	c := csv.NewWriter(w)
+	c.Write(genomeCSVHeader())
+	c.Write(genomeCSVRecord(as.frame, as.ants.genome))
+	c.Flush()
+	return c.Error()
+}
+
+// genomeSummary is a one line description of the mean genome, for the HUD.
+func genomeSummary(gs []genome) string {
+	var s string
+	for i, st := range genomeStats(gs) {
+		s += fmt.Sprintf("%s: %.1f ", genes[i].name, st.mean)
+	}
+	return s
+}
+
+func (as *AntScene) saveGenomeStats() error {
+	path, err := captureName(as.st.captureDir, ".csv")
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = as.WriteGenomeStats(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	fmt.Printf("Saved genome stats %s\n", path)
+	return f.Close()
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestMutateBounds(t *testing.T) {
+	g := genome{sight: 1, senseOdds: 100, turnOdds: 0, marker: pheromoneMax, fade: 1}
+	for i := 0; i < 1000; i++ {
+		g = g.mutate(rand.Intn)
+		for _, gn := range genes {
+			if v := gn.get(&g); v < gn.min || v > gn.max {
+				t.Fatalf("Gene %s mutated out of bounds [%d, %d]: %d", gn.name, gn.min, gn.max, v)
+			}
+		}
+	}
+}
+
+func TestGenePoolWraps(t *testing.T) {
+	var p genePool
+	for i := 0; i < genePoolSize+10; i++ {
+		p.add(genome{sight: i})
+	}
+	if len(p.pool) != genePoolSize {
+		t.Fatalf("Pool should hold %d genomes, but holds %d", genePoolSize, len(p.pool))
+	}
+	if p.pool[0].sight != genePoolSize {
+		t.Errorf("Oldest genome should have been replaced, but pool[0] has sight %d", p.pool[0].sight)
+	}
+}
+
+func TestGenomeStats(t *testing.T) {
+	gs := []genome{{sight: 10}, {sight: 20, followWalls: true}}
+	stats := genomeStats(gs)
+	if s := stats[0]; s.mean != 15 || s.min != 10 || s.max != 20 || s.stddev != 5 {
+		t.Errorf("Unexpected sight stats: %+v", s)
+	}
+	if s := stats[len(stats)-1]; s.mean != 0.5 {
+		t.Errorf("Half the ants follow walls, but mean is %f", s.mean)
+	}
+}
+
+func TestSpawnGenomes(t *testing.T) {
+	st := testState()
+	st.maxants = 50 * st.antlife
+	as, err := newHeadlessScene(&st, int64(st.antlife)*int64(st.spawnparam)*50, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(as.Close)
+	as.rng = rand.New(rand.NewSource(1))
+	as.Step()
+	if as.ants.len() < 20 {
+		t.Fatalf("Expected at least 20 ants to have spawned, got %d", as.ants.len())
+	}
+	cg := st.casteGenomes()
+	for i := 0; i < as.ants.len(); i++ {
+		if g := as.ants.genome[i]; g != cg[as.ants.caste[i]] {
+			t.Fatalf("Expected ant %d to get its caste's genome when not evolving, got %+v", i, g)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+type headlessOpts struct {
+	ticks    int    // Number of ticks to run the simulation for
+	every    int    // Render a frame every this many ticks
+	outDir   string // Directory to write PNG frames into. Empty means don't write them.
+	gifPath  string // File to write the animated GIF to. Empty means no GIF.
+	gifDelay int    // Delay between GIF frames, in 100ths of a second
+	grid     string // Grid file to load before starting
+	genomes  string // File to write genome statistics to every rendered frame. Empty means don't.
+	trips    string // File to write trip statistics to every rendered frame. Empty means don't.
+}
+
+// newHeadlessScene creates an AntScene that can be stepped without a window.
+func newHeadlessScene(st *GameState, homelife int64, grid string) (*AntScene, error) {
+	as := &AntScene{homelife: homelife, quiet: true}
+	err := as.initSim(st.width, st.height, st)
+	if err != nil {
+		return nil, err
+	}
+	if grid != "" {
+		err = as.LoadGridFile(grid)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return as, nil
+}
+
+// runHeadless runs the simulation for o.ticks ticks without opening a window,
+// rendering the field and ants every o.every ticks.
+func runHeadless(st GameState, homelife int64, o headlessOpts) error {
+	as, err := newHeadlessScene(&st, homelife, o.grid)
+	if err != nil {
+		return err
+	}
+	if o.every <= 0 {
+		o.every = 1
+	}
+	if o.outDir != "" {
+		err = os.MkdirAll(o.outDir, 0755)
+		if err != nil {
+			return err
+		}
+	}
+
+	var genomes *csv.Writer
+	if o.genomes != "" {
+		f, err := os.Create(o.genomes)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		genomes = csv.NewWriter(f)
+		genomes.Write(genomeCSVHeader())
+		defer genomes.Flush()
+	}
+
+	var trips *csv.Writer
+	if o.trips != "" {
+		f, err := os.Create(o.trips)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		trips = csv.NewWriter(f)
+		trips.Write(tripCSVHeader())
+		defer trips.Flush()
+	}
+
+	var anim gif.GIF
+	for t := 1; t <= o.ticks; t++ {
+		as.Step()
+		if t%o.every != 0 {
+			continue
+		}
+		img := as.Snapshot()
+		if o.outDir != "" {
+			err = writePNG(filepath.Join(o.outDir, fmt.Sprintf("frame-%06d.png", t)), img)
+			if err != nil {
+				return err
+			}
+		}
+		if o.gifPath != "" {
+			anim.Image = append(anim.Image, toPaletted(img))
+			anim.Delay = append(anim.Delay, o.gifDelay)
+		}
+		if genomes != nil {
+			genomes.Write(genomeCSVRecord(as.frame, as.ants.genome))
+		}
+		if trips != nil {
+			trips.Write(as.trips.csvRecord(as.frame, &as.ants))
+		}
+		fmt.Printf("tick: %d, hive life: %d, ants: %d\n", t, as.homelife, as.ants.len())
+	}
+
+	if o.gifPath != "" {
+		return writeGIF(o.gifPath, &anim)
+	}
+	return nil
+}
+
+// Snapshot renders the field and the ants into a new image the size of the
+// world image, using the same colours and layout as the on-screen renderer.
+func (as *AntScene) Snapshot() *image.RGBA {
+	as.field.UpdateAll()
+	buf := as.field.renderbuf
+	if as.hexGrid() {
+		buf = as.field.renderHexBuf()
+	}
+	w, h := as.worldSize()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i, c := range buf {
+		if c == 0 {
+			// Empty cells are transparent on screen, which shows black.
+			c = 0xFF000000
+		}
+		binary.LittleEndian.PutUint32(img.Pix[i*4:], c)
+	}
+
+	if as.st.renderAnts {
+		for a, p := range as.ants.pos {
+			c := antColor
+			if as.ants.food[a] > 0 {
+				c = antFullColor
+			}
+			as.wrapCopies(p, func(x, y int) {
+				x0, y0 := as.worldPixel(point{x, y})
+				x0 -= antTexSize / 2
+				y0 -= antTexSize / 2
+				drawAntTexture(as.ants.dir[a], func(x, y int) {
+					img.SetRGBA(x0+x, y0+y, c)
+				})
+			})
+		}
+	}
+	return img
+}
+
+func toPaletted(img image.Image) *image.Paletted {
+	p := image.NewPaletted(img.Bounds(), palette.Plan9)
+	draw.Draw(p, p.Rect, img, img.Bounds().Min, draw.Src)
+	return p
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = png.Encode(f, img)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeGIF(path string, anim *gif.GIF) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = gif.EncodeAll(f, anim)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
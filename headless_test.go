@@ -0,0 +1,128 @@
+package main
+
+import (
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func headlessTestScene(t *testing.T, w, h int) *AntScene {
+	t.Helper()
+	st := NewGameState(w, h)
+	as, err := newHeadlessScene(&st, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(as.Close)
+	return as
+}
+
+func TestSnapshot(t *testing.T) {
+	as := headlessTestScene(t, 120, 100)
+	as.field.Get(105, 5).Wall = false
+	as.field.Get(50, 50).Food = 10
+	as.ants.add(Ant{pos: point{20, 20}, dir: E})
+
+	img := as.Snapshot()
+	if b := img.Bounds(); b.Dx() != 120 || b.Dy() != 100 {
+		t.Fatalf("Expected a 120x100 snapshot, got %v", b)
+	}
+	for _, c := range []struct {
+		p    point
+		want color.RGBA
+	}{
+		{point{110, 50}, color.RGBA{0x33, 0x33, 0x33, 0xFF}}, // Wall
+		{point{5, 5}, color.RGBA{0xFF, 0x33, 0x33, 0xFF}},    // Hive
+		{point{50, 50}, color.RGBA{0x33, 0xFF, 0x33, 0xFF}},  // Food
+		{point{105, 5}, color.RGBA{0, 0, 0, 0xFF}},           // Empty
+	} {
+		if got := img.RGBAAt(c.p.x, c.p.y); got != c.want {
+			t.Errorf("Expected %v at %v, got %v", c.want, c.p, got)
+		}
+	}
+	n := 0
+	drawAntTexture(E, func(x, y int) {
+		if img.RGBAAt(20-antTexSize/2+x, 20-antTexSize/2+y) == antColor {
+			n++
+		}
+	})
+	if n == 0 {
+		t.Errorf("Expected the ant to be drawn")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "frame.png")
+	if err := writePNG(path, img); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	back, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []point{{110, 50}, {5, 5}, {50, 50}, {105, 5}} {
+		if got, want := color.RGBAModel.Convert(back.At(p.x, p.y)), img.At(p.x, p.y); got != want {
+			t.Errorf("Expected %v at %v in the PNG, got %v", want, p, got)
+		}
+	}
+
+	var anim gif.GIF
+	for i := 0; i < 2; i++ {
+		anim.Image = append(anim.Image, toPaletted(img))
+		anim.Delay = append(anim.Delay, 5)
+	}
+	path = filepath.Join(dir, "anim.gif")
+	if err := writeGIF(path, &anim); err != nil {
+		t.Fatal(err)
+	}
+	g, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	decoded, err := gif.DecodeAll(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Image) != 2 || decoded.Image[0].Bounds() != img.Bounds() {
+		t.Errorf("Expected 2 frames of %v, got %d of %v", img.Bounds(), len(decoded.Image), decoded.Image[0].Bounds())
+	}
+}
+
+func TestGridFileRoundTrip(t *testing.T) {
+	as := headlessTestScene(t, 120, 100)
+	as.field.Get(105, 5).Wall = false
+	as.field.Get(105, 6).Food = 42
+	path := filepath.Join(t.TempDir(), "grid")
+	if err := as.SaveGridFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	as.field.Clear()
+	if err := as.LoadGridFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if g := as.field.Get(105, 5); g.Wall {
+		t.Errorf("Expected (105, 5) to have been loaded clear, got %+v", g)
+	}
+	if g := as.field.Get(105, 6); g.Food != 42 || !g.Wall {
+		t.Errorf("Expected (105, 6) to have been loaded as a wall with 42 food, got %+v", g)
+	}
+	if g := as.field.Get(5, 5); !g.Home {
+		t.Errorf("Expected (5, 5) to have been loaded as hive, got %+v", g)
+	}
+
+	small := headlessTestScene(t, 110, 100)
+	err := small.LoadGridFile(path)
+	if err == nil || !strings.Contains(err.Error(), "12000 cells") {
+		t.Errorf("Expected loading a 120x100 grid into a 110x100 field to fail, got %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// heatLayer is a heatmap drawn over the field, of where things have happened
+// recently.
+type heatLayer int
+
+const (
+	layerOff heatLayer = iota
+	layerVisits
+	layerDeaths
+	layerPickups
+	layerDirection
+	endLayer
+)
+
+func (l heatLayer) String() string {
+	switch l {
+	case layerOff:
+		return "Off"
+	case layerVisits:
+		return "Visits"
+	case layerDeaths:
+		return "Deaths"
+	case layerPickups:
+		return "Food Pickups"
+	case layerDirection:
+		return "Average Direction"
+	}
+	return "UNKNOWN"
+}
+
+// heatCell counts the events in a cell over the current window and the one
+// before it, so what's shown always covers at least one whole window.
+type heatCell struct {
+	n      [2]float32
+	dx, dy [2]float32 // Summed headings of visits, for the direction layer
+}
+
+// heatmap accumulates a single layer. Only the layer being shown is recorded.
+type heatmap struct {
+	layer heatLayer
+	field *Field[heatCell]
+	cur   int    // Which of each cell's counts is the current window
+	start uint64 // Frame the current window started on
+	max   float32
+	img   *ebiten.Image
+}
+
+func newHeatmap(as *AntScene, l heatLayer) (*heatmap, error) {
+	hm := &heatmap{layer: l, start: as.frame}
+	f, err := NewField[heatCell](as.field.width, as.field.height, func(c *heatCell) uint32 {
+		return hm.color(as.st, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	hm.field = f
+	return hm, nil
+}
+
+// syncHeatmap starts recording the layer chosen in the menu, if it isn't
+// already. If the heatmap can't be made, it's turned off.
+func (as *AntScene) syncHeatmap() {
+	switch {
+	case as.st.heatmap == layerOff:
+		as.heat = nil
+	case as.heat == nil || as.heat.layer != as.st.heatmap:
+		hm, err := newHeatmap(as, as.st.heatmap)
+		if err != nil {
+			fmt.Printf("Failed to create heatmap: %v\n", err)
+			as.st.heatmap = layerOff
+		}
+		as.heat = hm
+	}
+}
+
+// record counts an event of layer l at p, by an ant with heading h. It only
+// touches p's cell, so it's safe to call from Commit.
+func (hm *heatmap) record(l heatLayer, p point, h float64) {
+	if hm == nil {
+		return
+	}
+	if l == layerVisits && hm.layer == layerDirection {
+		l = layerDirection
+	}
+	if l != hm.layer {
+		return
+	}
+	c := hm.field.Get(p.x, p.y)
+	c.n[hm.cur]++
+	if l == layerDirection {
+		c.dx[hm.cur] += float32(math.Sin(h))
+		c.dy[hm.cur] -= float32(math.Cos(h))
+	}
+}
+
+// tick starts a new window every window frames, forgetting the oldest one.
+func (hm *heatmap) tick(frame uint64, window int) {
+	if window < 1 {
+		window = 1
+	}
+	if frame-hm.start < uint64(window) {
+		return
+	}
+	hm.start = frame
+	hm.cur ^= 1
+	for i := range hm.field.vals {
+		c := &hm.field.vals[i]
+		c.n[hm.cur], c.dx[hm.cur], c.dy[hm.cur] = 0, 0, 0
+	}
+}
+
+// color colours a cell. Counts are scaled against the busiest cell with the
+// pheromone scale, and coloured with the palette if it's a single colour map
+// or magma otherwise. Directions are shown as hues, brighter where the ants
+// passing through agree more.
+func (hm *heatmap) color(st *GameState, c *heatCell) uint32 {
+	n := c.n[0] + c.n[1]
+	if n == 0 {
+		return 0
+	}
+	if hm.layer == layerDirection {
+		dx, dy := c.dx[0]+c.dx[1], c.dy[0]+c.dy[1]
+		hue := math.Atan2(float64(dx), float64(-dy)) / (2 * math.Pi)
+		return hsv(hue, math.Hypot(float64(dx), float64(dy))/float64(n))
+	}
+	p := st.palette
+	if p.mixed() {
+		p = paletteMagma
+	}
+	l := st.pherScale.level(int(float32(pheromoneMax) * n / hm.max))
+	return p.color(l, 0)
+}
+
+// hsv returns the fully saturated colour of hue h (in turns) and value v,
+// packed like renderbuf.
+func hsv(h, v float64) uint32 {
+	h = (h - math.Floor(h)) * 6
+	var rgb [3]float64
+	for ch, off := range [3]float64{5, 3, 1} {
+		k := math.Mod(off+h, 6)
+		rgb[ch] = v * (1 - math.Max(0, math.Min(1, math.Min(k, 4-k))))
+	}
+	return 0xFF000000 | uint32(rgb[2]*255+0.5)<<16 | uint32(rgb[1]*255+0.5)<<8 | uint32(rgb[0]*255+0.5)
+}
+
+// draw draws the heatmap over screen.
+func (hm *heatmap) draw(as *AntScene, screen *ebiten.Image) error {
+	hm.max = 0
+	for i := range hm.field.vals {
+		c := &hm.field.vals[i]
+		if n := c.n[0] + c.n[1]; n > hm.max {
+			hm.max = n
+		}
+	}
+	hm.field.UpdateAll()
+	ww, wh := as.worldSize()
+	hm.img = sizedImage(hm.img, ww, wh)
+	var err error
+	if as.hexGrid() {
+		hm.img.Clear()
+		err = hm.field.RenderHex(hm.img)
+	} else {
+		err = hm.field.Render(hm.img)
+	}
+	if err != nil {
+		return err
+	}
+	screen.DrawImage(hm.img, nil)
+	return nil
+}
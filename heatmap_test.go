@@ -0,0 +1,75 @@
+package main
+
+import "testing"
+
+func TestHeatmapWindows(t *testing.T) {
+	as := testScene(t, 10, 10)
+	hm, err := newHeatmap(as, layerVisits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := point{3, 4}
+	count := func() float32 {
+		c := hm.field.Get(p.x, p.y)
+		return c.n[0] + c.n[1]
+	}
+
+	hm.record(layerVisits, p, 0)
+	hm.record(layerPickups, p, 0)
+	hm.record(layerVisits, p, 0)
+	if n := count(); n != 2 {
+		t.Errorf("Expected only the 2 visits to be recorded, got %f", n)
+	}
+	hm.tick(9, 10)
+	if n := count(); n != 2 {
+		t.Errorf("Expected the visits to last the window, got %f", n)
+	}
+	hm.tick(10, 10)
+	hm.record(layerVisits, p, 0)
+	if n := count(); n != 3 {
+		t.Errorf("Expected the visits to last into the next window, got %f", n)
+	}
+	hm.tick(20, 10)
+	if n := count(); n != 1 {
+		t.Errorf("Expected the first window's visits to be forgotten, got %f", n)
+	}
+}
+
+func TestHeatmapDirection(t *testing.T) {
+	as := testScene(t, 10, 10)
+	hm, err := newHeatmap(as, layerDirection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := point{1, 1}
+	for i := 0; i < 3; i++ {
+		hm.record(layerVisits, p, dirAngle(E))
+	}
+	c := hm.field.Get(p.x, p.y)
+	if got, want := hm.color(as.st, c), hsv(0.25, 1); got != want {
+		t.Errorf("Ants all heading east should show as hue 0.25 at full brightness (%08X), got %08X", want, got)
+	}
+	hm.record(layerVisits, p, dirAngle(W))
+	hm.record(layerVisits, p, dirAngle(W))
+	hm.record(layerVisits, p, dirAngle(W))
+	if got := hm.color(as.st, c); got&0xFFFFFF > 0x010101 {
+		t.Errorf("Ants heading both ways should cancel out to black, got %08X", got)
+	}
+	if got := hsv(0, 1); got != 0xFF0000FF {
+		t.Errorf("Hue 0 should be red, got %08X", got)
+	}
+}
+
+func TestHeatmapDeaths(t *testing.T) {
+	as := testScene(t, 10, 10)
+	as.st.heatmap = layerDeaths
+	as.ants.add(Ant{pos: point{5, 6}, life: 1})
+	as.Step()
+	as.Step()
+	if as.ants.len() != 0 {
+		t.Fatalf("Expected the ant to have died")
+	}
+	if c := as.heat.field.Get(5, 6); c.n[0]+c.n[1] != 1 {
+		t.Errorf("Expected a death at (5, 6), got %+v", *c)
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// The hex grid is stored in the same rectangular field as the square grid,
+// using "odd-r" offset coordinates: odd rows are shifted half a cell to the
+// right. Of the eight directions, N and S have no neighbour, leaving the six
+// in hexDirs.
+//
+// On screen each cell is drawn as a pointy topped hexagon hexScale pixels
+// across, with rows hexPitch (sqrt(3)/2 of a cell) apart, so every cell's six
+// neighbours are drawn the same distance away. The world image is then
+// larger than the field, and everything drawn over the field goes through
+// worldPos and worldCell.
+//
+// If the world wraps, the field needs an even height for rows to line up
+// across the top and bottom edges. fitHexWrap sees to that.
+
+const hexScale = 2
+
+var (
+	hexRadius = hexScale / math.Sqrt(3) // Centre to corner of a drawn cell
+	hexPitch  = hexRadius * 1.5         // Between the centres of drawn rows
+)
+
+var hexDirs = [6]direction{NE, E, SE, SW, W, NW}
+
+// hexIndex is the index of each direction in hexDirs. N and S round clockwise.
+var hexIndex = [END]int{N: 0, NE: 0, E: 1, SE: 2, S: 3, SW: 3, W: 4, NW: 5}
+
+// HexAt returns the neighbouring cell in direction d on the hex grid.
+func (p point) HexAt(d direction) point {
+	odd := p.y & 1
+	switch hexDirs[hexIndex[d]] {
+	case NE:
+		return point{p.x + odd, p.y - 1}
+	case E:
+		return point{p.x + 1, p.y}
+	case SE:
+		return point{p.x + odd, p.y + 1}
+	case SW:
+		return point{p.x + odd - 1, p.y + 1}
+	case W:
+		return point{p.x - 1, p.y}
+	case NW:
+		return point{p.x + odd - 1, p.y - 1}
+	}
+	return p
+}
+
+// fitHexWrap rounds the height up to an even number if the world wraps on the
+// hex grid, and reports whether it had to.
+func (st *GameState) fitHexWrap() bool {
+	if !st.hex || !st.wrap || st.height%2 == 0 {
+		return false
+	}
+	st.height++
+	return true
+}
+
+// hexGrid reports whether ants move on the hex grid. Continuous movement
+// doesn't use the grid's directions, so it ignores the topology.
+func (as *AntScene) hexGrid() bool {
+	return as.st.hex && !as.st.continuous
+}
+
+// dirCount is the number of directions an ant can face.
+func (as *AntScene) dirCount() int {
+	if as.hexGrid() {
+		return len(hexDirs)
+	}
+	return int(END)
+}
+
+// step returns the neighbouring point in direction d for the current topology.
+func (as *AntScene) step(p point, d direction) point {
+	if as.hexGrid() {
+		return p.HexAt(d)
+	}
+	return p.PointAt(d)
+}
+
+// right turns d clockwise by n of the current topology's directions.
+func (as *AntScene) right(d direction, n int) direction {
+	if as.hexGrid() {
+		return hexDirs[wrapi(hexIndex[d]+n, len(hexDirs))]
+	}
+	return d.Right(n)
+}
+
+func (as *AntScene) left(d direction, n int) direction {
+	return as.right(d, -n)
+}
+
+// hexWorldSize returns the size in pixels of a w by h cell hex grid.
+func hexWorldSize(w, h int) (int, int) {
+	return w*hexScale + hexScale/2, int(math.Ceil(float64(h-1)*hexPitch + 2*hexRadius))
+}
+
+// hexCentre returns where the centre of cell p is drawn.
+func hexCentre(p point) (float64, float64) {
+	return (float64(p.x) + 0.5 + 0.5*float64(p.y&1)) * hexScale, float64(p.y)*hexPitch + hexRadius
+}
+
+// hexCellAt returns the cell whose hexagon covers (x, y), which may be off
+// the field.
+func hexCellAt(x, y float64) point {
+	// Axial coordinates, relative to the centre of (0, 0), rounded to the
+	// nearest cell in cube coordinates.
+	x -= hexScale / 2.0
+	y -= hexRadius
+	q := (x*math.Sqrt(3)/3 - y/3) / hexRadius
+	r := y * 2 / 3 / hexRadius
+	s := -q - r
+	rq, rr, rs := math.Round(q), math.Round(r), math.Round(s)
+	dq, dr, ds := math.Abs(rq-q), math.Abs(rr-r), math.Abs(rs-s)
+	if dq > dr && dq > ds {
+		rq = -rr - rs
+	} else if dr > ds {
+		rr = -rq - rs
+	}
+	row := int(rr)
+	return point{int(rq) + (row-row&1)/2, row}
+}
+
+// worldSize returns the size in pixels of the image the world is drawn into.
+func (as *AntScene) worldSize() (int, int) {
+	if as.hexGrid() {
+		return hexWorldSize(as.field.width, as.field.height)
+	}
+	return as.field.width, as.field.height
+}
+
+// worldPos returns where the centre of cell p is drawn in the world image.
+func (as *AntScene) worldPos(p point) (float64, float64) {
+	if as.hexGrid() {
+		return hexCentre(p)
+	}
+	return float64(p.x) + 0.5, float64(p.y) + 0.5
+}
+
+// worldPixel returns the pixel of the world image at the centre of cell p.
+func (as *AntScene) worldPixel(p point) (int, int) {
+	x, y := as.worldPos(p)
+	return int(math.Floor(x)), int(math.Floor(y))
+}
+
+// worldCell returns the cell drawn at pixel (x, y) of the world image.
+func (as *AntScene) worldCell(x, y int) point {
+	if as.hexGrid() {
+		return hexCellAt(float64(x)+0.5, float64(y)+0.5)
+	}
+	return point{x, y}
+}
+
+// hexTable returns the index of the cell drawn at each pixel of the hex
+// world, or -1 where there's none. It's worked out on first use.
+func (f *Field[T]) hexTable() []int32 {
+	if f.hexIdx != nil {
+		return f.hexIdx
+	}
+	w, h := hexWorldSize(f.width, f.height)
+	f.hexIdx = make([]int32, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := int32(-1)
+			if p := hexCellAt(float64(x)+0.5, float64(y)+0.5); p.Within(0, 0, f.width, f.height) {
+				i = int32(p.x + p.y*f.width)
+			}
+			f.hexIdx[x+y*w] = i
+		}
+	}
+	return f.hexIdx
+}
+
+// renderHexBuf draws the field's colours as hexagons into the hex world
+// sized f.hexbuf.
+func (f *Field[T]) renderHexBuf() []uint32 {
+	idx := f.hexTable()
+	if f.hexbuf == nil {
+		f.hexbuf = make([]uint32, len(idx))
+	}
+	for i, c := range idx {
+		if c < 0 {
+			f.hexbuf[i] = 0
+		} else {
+			f.hexbuf[i] = f.renderbuf[c]
+		}
+	}
+	return f.hexbuf
+}
+
+// RenderHex draws the field as a hex grid onto r, which must be the size
+// hexWorldSize gives.
+func (f *Field[T]) RenderHex(r *ebiten.Image) error {
+	r.WritePixels(pixelBytes(f.renderHexBuf()))
+	return nil
+}
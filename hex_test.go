@@ -0,0 +1,148 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestHexNeighbours(t *testing.T) {
+	for _, p := range []point{{5, 4}, {5, 5}} {
+		seen := map[point]bool{}
+		for i, d := range hexDirs {
+			n := p.HexAt(d)
+			if seen[n] {
+				t.Errorf("%v: %s leads to %v, which another direction already does", p, d, n)
+			}
+			seen[n] = true
+			back := hexDirs[(i+3)%len(hexDirs)]
+			if b := n.HexAt(back); b != p {
+				t.Errorf("%v: going %s then %s should come back, but ended at %v", p, d, back, b)
+			}
+		}
+	}
+}
+
+func TestHexTurns(t *testing.T) {
+	as := testScene(t, 40, 40)
+	as.st.hex = true
+	d := NE
+	for i := 0; i < len(hexDirs); i++ {
+		d = as.right(d, 1)
+	}
+	if d != NE {
+		t.Errorf("Six right turns should come back to NE, but ended at %s", d)
+	}
+	if d := as.right(E, 3); d != W {
+		t.Errorf("Turning E around should face W, but faced %s", d)
+	}
+	if d := as.left(NE, 1); d != NW {
+		t.Errorf("Left of NE should be NW, but was %s", d)
+	}
+}
+
+func TestHexGeometry(t *testing.T) {
+	for _, p := range []point{{5, 4}, {5, 5}, {0, 0}, {-1, -1}} {
+		x, y := hexCentre(p)
+		for _, d := range hexDirs {
+			nx, ny := hexCentre(p.HexAt(d))
+			if dist := math.Hypot(nx-x, ny-y); math.Abs(dist-hexScale) > 1e-9 {
+				t.Errorf("%v: expected the %s neighbour to be drawn %d pixels away, got %f", p, d, hexScale, dist)
+			}
+		}
+		if c := hexCellAt(x, y); c != p {
+			t.Errorf("Expected the centre of %v to be in it, got %v", p, c)
+		}
+	}
+}
+
+func TestHexTable(t *testing.T) {
+	f, err := NewField[gridspot](30, 20, func(*gridspot) uint32 { return 0 })
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make([]int, 30*20)
+	for _, i := range f.hexTable() {
+		if i >= 0 {
+			seen[i]++
+		}
+	}
+	for i, n := range seen {
+		// A hexagon covers about 3.5 pixels.
+		if n < 2 || n > 5 {
+			t.Errorf("Expected cell (%d, %d) to be drawn with 2 to 5 pixels, got %d", i%30, i/30, n)
+		}
+	}
+}
+
+func TestHexSnapshot(t *testing.T) {
+	st := NewGameState(120, 100)
+	st.hex = true
+	st.renderAnts = false
+	as, err := newHeadlessScene(&st, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer as.Close()
+	as.field.Get(50, 51).Food = 10
+
+	img := as.Snapshot()
+	w, h := hexWorldSize(120, 100)
+	if b := img.Bounds(); b.Dx() != w || b.Dy() != h {
+		t.Fatalf("Expected a %dx%d hex snapshot, got %v", w, h, b)
+	}
+	x, y := as.worldPixel(point{50, 51})
+	if c := img.RGBAAt(x, y); c != (color.RGBA{0x33, 0xFF, 0x33, 0xFF}) {
+		t.Errorf("Expected food drawn at the centre of (50, 51), got %v", c)
+	}
+	if c := as.worldCell(x, y); c != (point{50, 51}) {
+		t.Errorf("Expected the centre pixel of (50, 51) to map back to it, got %v", c)
+	}
+	// Its neighbours to the west and east aren't food.
+	for _, d := range []direction{W, E} {
+		x, y := as.worldPixel(point{50, 51}.HexAt(d))
+		if c := img.RGBAAt(x, y); c.G == 0xFF {
+			t.Errorf("Expected no food %s of (50, 51), got %v", d, c)
+		}
+	}
+}
+
+// hexWrapConsistent reports whether every step across the top and bottom
+// edges of a wrapping hex field comes back the way it went.
+func hexWrapConsistent(t *testing.T, h int) bool {
+	as := testScene(t, 10, h)
+	as.st.hex, as.st.wrap = true, true
+	for x := 0; x < 10; x++ {
+		for _, y := range []int{0, h - 1} {
+			p := point{x, y}
+			for i, d := range hexDirs {
+				n, _ := as.cell(as.step(p, d))
+				if b, _ := as.cell(as.step(n, hexDirs[(i+3)%len(hexDirs)])); b != p {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+func TestFitHexWrap(t *testing.T) {
+	if hexWrapConsistent(t, 11) {
+		t.Errorf("Expected an odd height to wrap inconsistently on the hex grid")
+	}
+	st := NewGameState(10, 11)
+	st.hex = true
+	if st.fitHexWrap() {
+		t.Errorf("Expected the height to be left alone without wrap")
+	}
+	st.wrap = true
+	if !st.fitHexWrap() || st.height != 12 {
+		t.Errorf("Expected an odd height to be rounded up to 12, got %d", st.height)
+	}
+	if st.fitHexWrap() {
+		t.Errorf("Expected an even height to be left alone")
+	}
+	if !hexWrapConsistent(t, st.height) {
+		t.Errorf("Expected height %d to wrap consistently on the hex grid", st.height)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	inspectPathLen   = 1000 // Number of recent positions drawn behind the inspected ant
+	inspectTripsLen  = 8    // Number of trip events listed in the panel
+	inspectPickRange = 8    // How far from an ant a click can be and still select it
+	inspectPanelW    = 300
+)
+
+var (
+	inspectPanelColor = color.RGBA{A: 0xbf}
+	inspectColor      = color.RGBA{R: 0x55, G: 0xff, B: 0xff, A: 0xff}
+)
+
+// inspector follows a single ant, recording where it's been and the trips it
+// has made, and draws its state in a side panel.
+type inspector struct {
+	id    uint64
+	alive bool
+	ant   Ant // The ant's state as of the last tick
+	died  uint64
+	path  []point
+	trips []string
+}
+
+// nearestAnt returns the ant closest to (x, y), if there's one within
+// inspectPickRange.
+func (as *AntScene) nearestAnt(x, y int) (int, bool) {
+	best := -1
+	bestDist := inspectPickRange * inspectPickRange
+	for a, p := range as.ants.pos {
+		dx := p.x - x
+		dy := p.y - y
+		if d := dx*dx + dy*dy; d <= bestDist {
+			best = a
+			bestDist = d
+		}
+	}
+	return best, best >= 0
+}
+
+// selectAnt starts inspecting the ant closest to (x, y), if there's one close
+// enough. Otherwise it stops inspecting.
+func (as *AntScene) selectAnt(x, y int) {
+	best, ok := as.nearestAnt(x, y)
+	if !ok {
+		as.inspect = nil
+		return
+	}
+	as.inspect = &inspector{id: as.ants.id[best], alive: true, ant: as.ants.get(best)}
+}
+
+// observe records the inspected ant's state after a tick.
+func (in *inspector) observe(as *AntScene) {
+	if !in.alive {
+		return
+	}
+	i, ok := as.ants.find(in.id)
+	if !ok {
+		in.alive = false
+		in.died = as.frame
+		in.ant.life = 0
+		return
+	}
+
+	prev := in.ant
+	in.ant = as.ants.get(i)
+	a := &in.ant
+	if prev.food == 0 && a.food > 0 {
+		in.trip(fmt.Sprintf("%d: picked up %d food at (%d,%d)", as.frame, a.food, a.pos.x, a.pos.y))
+	} else if prev.food > 0 && a.food == 0 {
+		in.trip(fmt.Sprintf("%d: delivered %d food", as.frame, prev.food))
+	}
+
+	if len(in.path) == 0 || in.path[len(in.path)-1] != a.pos {
+		in.path = append(in.path, a.pos)
+		if len(in.path) > inspectPathLen {
+			in.path = in.path[len(in.path)-inspectPathLen:]
+		}
+	}
+}
+
+func (in *inspector) trip(s string) {
+	in.trips = append(in.trips, s)
+	if len(in.trips) > inspectTripsLen {
+		in.trips = in.trips[len(in.trips)-inspectTripsLen:]
+	}
+}
+
+func (in *inspector) lines() []string {
+	a := &in.ant
+	status := "alive"
+	if !in.alive {
+		status = fmt.Sprintf("died at tick %d", in.died)
+	}
+	ls := []string{
+		fmt.Sprintf("Ant #%d (%s), %s", in.id, a.caste, status),
+		fmt.Sprintf("Position: (%d, %d)", a.pos.x, a.pos.y),
+		fmt.Sprintf("Direction: %s", a.dir),
+		fmt.Sprintf("Food: %d", a.food),
+		fmt.Sprintf("Marker: %d", a.marker),
+		fmt.Sprintf("Life: %d", a.life),
+		fmt.Sprintf("Age: %d", a.age),
+		fmt.Sprintf("Completed trips: %d, current one %.0f cells so far", a.trips, a.tripDist),
+		fmt.Sprintf("Since pickup: %d ticks", a.sincePickup),
+		"Trips:",
+	}
+	if len(in.trips) == 0 {
+		ls = append(ls, "  none yet")
+	}
+	for _, t := range in.trips {
+		ls = append(ls, "  "+t)
+	}
+	return ls
+}
+
+// drawWorld draws the ant's path and a box around it onto the world.
+func (in *inspector) drawWorld(as *AntScene, world *ebiten.Image) {
+	for i := 1; i < len(in.path); i++ {
+		p0, p1 := in.path[i-1], in.path[i]
+		if as.st.wrap && (absi(p1.x-p0.x) > as.field.width/2 || absi(p1.y-p0.y) > as.field.height/2) {
+			// The ant went over an edge.
+			continue
+		}
+		x0, y0 := as.worldPixel(p0)
+		x1, y1 := as.worldPixel(p1)
+		doLine(x0, y0, x1, y1, func(x, y int) {
+			world.Set(x, y, inspectColor)
+		})
+	}
+	if in.alive {
+		const box = antTexSize + 4
+		x, y := as.worldPixel(in.ant.pos)
+		vector.StrokeRect(world, float32(x-box/2), float32(y-box/2), box, box, 1, inspectColor, false)
+	}
+}
+
+// draw draws the panel describing the ant onto the screen.
+func (in *inspector) draw(as *AntScene, screen *ebiten.Image) {
+	ls := in.lines()
+	w := screen.Bounds().Dx()
+	x := w - inspectPanelW
+	vector.DrawFilledRect(screen, float32(x), 0, inspectPanelW, float32((len(ls)+1)*panelFontSpace), inspectPanelColor, false)
+	y := panelFontSpace
+	for _, l := range ls {
+		text.Draw(screen, l, as.panelFont, x+10, y, color.White)
+		y += panelFontSpace
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelectAnt(t *testing.T) {
+	as := testScene(t, 100, 100)
+	as.ants.add(Ant{id: 1, pos: point{10, 10}})
+	as.ants.add(Ant{id: 2, pos: point{14, 10}})
+	as.ants.add(Ant{id: 3, pos: point{50, 50}})
+
+	as.selectAnt(13, 11)
+	if as.inspect == nil || as.inspect.id != 2 {
+		t.Fatalf("Expected the nearest ant, #2, to be selected, got %+v", as.inspect)
+	}
+	as.selectAnt(50+inspectPickRange, 50)
+	if as.inspect == nil || as.inspect.id != 3 {
+		t.Errorf("Expected an ant just in range to be selected, got %+v", as.inspect)
+	}
+	as.selectAnt(80, 80)
+	if as.inspect != nil {
+		t.Errorf("Expected clicking away from every ant to stop inspecting, got %+v", as.inspect)
+	}
+}
+
+func TestObserve(t *testing.T) {
+	as := testScene(t, 100, 100)
+	as.ants.add(Ant{id: 7, pos: point{10, 10}})
+	as.selectAnt(10, 10)
+	in := as.inspect
+
+	as.frame = 1
+	as.ants.food[0] = 10
+	as.ants.pos[0] = point{11, 10}
+	in.observe(as)
+	as.frame = 2
+	as.ants.food[0] = 0
+	as.ants.pos[0] = point{12, 10}
+	in.observe(as)
+	as.frame = 3
+	in.observe(as)
+
+	if len(in.trips) != 2 || !strings.Contains(in.trips[0], "picked up 10 food at (11,10)") || !strings.Contains(in.trips[1], "2: delivered 10 food") {
+		t.Errorf("Expected a pickup then a delivery, got %q", in.trips)
+	}
+	if len(in.path) != 2 || in.path[1] != (point{12, 10}) {
+		t.Errorf("Expected a point in the path for each cell moved to, got %v", in.path)
+	}
+
+	as.ants.remove(0)
+	as.frame = 4
+	in.observe(as)
+	if in.alive || in.died != 4 {
+		t.Errorf("Expected the ant to be seen dying on tick 4, got alive %t died %d", in.alive, in.died)
+	}
+}
@@ -3,8 +3,11 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"runtime"
 	"runtime/pprof"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -18,6 +21,39 @@ const (
 	nants = 3000
 )
 
+var (
+	headless = flag.Bool("headless", false, "Run the simulation without a window, rendering frames to files")
+	ticks    = flag.Int("ticks", 10000, "Headless and sweep: number of ticks to run")
+	every    = flag.Int("every", 100, "Headless: render a frame every this many ticks")
+	outDir   = flag.String("out", "frames", "Headless: directory to write PNG frames to (empty to disable)")
+	gifPath  = flag.String("gif", "", "Headless: write the rendered frames to this animated GIF")
+	gifDelay = flag.Int("gifdelay", 10, "Headless: delay between GIF frames in 100ths of a second")
+	gridPath = flag.String("grid", "", "Headless and sweep: grid file to load before starting")
+	pher     = flag.Bool("pher", true, "Headless: render pheromones")
+	evolve   = flag.Bool("evolve", false, "Start with genome evolution turned on")
+	genomes  = flag.String("genomes", "", "Headless: write genome statistics CSV to this file every rendered frame")
+	tripsOut = flag.String("trips", "", "Headless: write trip statistics CSV to this file every rendered frame")
+
+	homelife = flag.Int64("homelife", 3000*10000*100, "Life the hive starts with")
+
+	sweep    = flag.String("sweep", "", "Run a headless parameter sweep, e.g. \"fadedivisor=300,700;sight=5:20:5\"")
+	seeds    = flag.Int("seeds", 3, "Sweep: runs per parameter combination, each with a different seed")
+	seed     = flag.Int64("seed", 1, "Sweep: seed of the first run of each combination")
+	jobs     = flag.Int("jobs", runtime.NumCPU(), "Sweep: number of runs to go at once")
+	sweepOut = flag.String("sweepout", "", "Sweep: file to write the summary table to (default stdout)")
+
+	optimize  = flag.String("optimize", "", "Search for the best parameters, e.g. \"fadedivisor=100:2000;sight=1:50\"")
+	objective = flag.String("objective", "delivered", "Optimise: what to maximise; delivered (food per tick), ants (mean population) or peak (peak population)")
+	method    = flag.String("method", "hill", "Optimise: search method, hill (hill climbing) or random")
+	evals     = flag.Int("evals", 100, "Optimise: number of configurations to try")
+	optOut    = flag.String("optout", "best.conf", "Optimise: file to write the best configuration to")
+
+	config = flag.String("config", "", "Load parameters from this config file")
+	world  = flag.String("world", "", "Size of the world, e.g. 2560x1440 (default the window's size)")
+
+	captureDir = flag.String("capturedir", "captures", "Directory screenshots (O) and clips (V) are saved to")
+)
+
 // type LineScene struct {
 // 	linex1 int32
 // 	liney1 int32
@@ -62,6 +98,7 @@ const (
 // func (s *LineScene) Destroy() {}
 
 func main() {
+	flag.Parse()
 	// if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
 	// 	panic(err)
 	// }
@@ -93,6 +130,44 @@ func main() {
 	}
 	defer pprof.StopCPUProfile()
 
+	if *optimize != "" {
+		err = doOptimize()
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *sweep != "" {
+		err = doSweep()
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *headless {
+		st, err := flagGameState()
+		if err != nil {
+			log.Fatal(err)
+		}
+		st.renderPher = *pher
+		err = runHeadless(st, *homelife, headlessOpts{
+			ticks:    *ticks,
+			every:    *every,
+			outDir:   *outDir,
+			gifPath:  *gifPath,
+			gifDelay: *gifDelay,
+			grid:     *gridPath,
+			genomes:  *genomes,
+			trips:    *tripsOut,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	//err = g.Run()
 	//fmt.Printf("Finished: %v\n", err)
 
@@ -104,9 +179,13 @@ func main() {
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 	ebiten.SetWindowTitle("Your game's title")
 
-	g := NewGame[GameState](WIDTH, HEIGHT, NewGameState(WIDTH, HEIGHT)) //&Game[GameState]{}
+	st, err := flagGameState()
+	if err != nil {
+		log.Fatal(err)
+	}
+	g := NewGame[GameState](WIDTH, HEIGHT, st) //&Game[GameState]{}
 	//as := &AntScene{homelife: 3000 * 10000}
-	as := &AntScene{homelife: 3000 * 10000 * 100}
+	as := &AntScene{homelife: *homelife}
 	err = g.PushScene(as)
 	if err != nil {
 		log.Fatal(err)
@@ -118,3 +197,101 @@ func main() {
 	}
 
 }
+
+func doSweep() error {
+	axes, err := parseSweep(*sweep)
+	if err != nil {
+		return err
+	}
+	st, err := flagGameState()
+	if err != nil {
+		return err
+	}
+	out := os.Stdout
+	if *sweepOut != "" {
+		out, err = os.Create(*sweepOut)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+	return runSweep(st, sweepOpts{axes: axes, runOpts: flagRunOpts()}, out)
+}
+
+func flagRunOpts() runOpts {
+	return runOpts{
+		ticks:    *ticks,
+		seeds:    *seeds,
+		seed:     *seed,
+		grid:     *gridPath,
+		homelife: *homelife,
+		jobs:     *jobs,
+	}
+}
+
+// flagGameState creates the starting GameState from the defaults, the config
+// file and the other flags.
+func flagGameState() (GameState, error) {
+	st := NewGameState(WIDTH, HEIGHT)
+	if *config != "" {
+		err := st.LoadConfigFile(*config)
+		if err != nil {
+			return st, fmt.Errorf("loading %s: %w", *config, err)
+		}
+	}
+	st.captureDir = *captureDir
+	if *world != "" {
+		var w, h int
+		if _, err := fmt.Sscanf(*world, "%dx%d", &w, &h); err != nil || w < 100 || h < 100 {
+			return st, fmt.Errorf("bad world size %q: want WIDTHxHEIGHT, each at least 100", *world)
+		}
+		st.width, st.height = w, h
+		// The minimap is how to get around a world bigger than the window.
+		st.minimap = w > WIDTH || h > HEIGHT
+	}
+	if st.fitHexWrap() {
+		fmt.Printf("Rounded the world's height up to %d so the hex grid can wrap\n", st.height)
+	}
+	if *evolve {
+		st.evolve = true
+	}
+	return st, nil
+}
+
+func doOptimize() error {
+	axes, err := parseSearchSpace(*optimize)
+	if err != nil {
+		return err
+	}
+	st, err := flagGameState()
+	if err != nil {
+		return err
+	}
+	best, score, err := runOptimize(st, optOpts{
+		runOpts:   flagRunOpts(),
+		axes:      axes,
+		objective: *objective,
+		method:    *method,
+		evals:     *evals,
+	})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(*optOut)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(f, "# Best %s score %f after %d configurations, %d ticks x %d seeds\n", *objective, score, *evals, *ticks, *seeds)
+	err = best.WriteConfig(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	fmt.Printf("Best %s score: %f\n", *objective, score)
+	for _, ax := range axes {
+		fmt.Printf("\t%s = %d\n", ax.p.name, ax.p.get(&best))
+	}
+	fmt.Printf("Wrote %s, load it with -config %s\n", *optOut, *optOut)
+	return f.Close()
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	minimapSize   = 200 // Longest side of the minimap, in pixels
+	minimapMargin = 10
+	minimapEvery  = 10 // The minimap is redrawn every this many frames
+)
+
+const minimapBackground = 0xFF101010
+
+// minimap shows the whole world shrunk down into a corner of the screen, with
+// the part of it on screen outlined. Each of its pixels shows the most
+// important thing in the block of cells under it: the hive, then food, then
+// wall. Ants tint it by how many of them are in the block.
+type minimap struct {
+	img    *ebiten.Image
+	buf    []uint32
+	ants   []int
+	w, h   int
+	scale  float64 // Cells per minimap pixel
+	frames int
+}
+
+func newMinimap(width, height int) *minimap {
+	scale := float64(width) / minimapSize
+	if s := float64(height) / minimapSize; s > scale {
+		scale = s
+	}
+	if scale < 1 {
+		scale = 1
+	}
+	w := int(float64(width)/scale + 0.5)
+	h := int(float64(height)/scale + 0.5)
+	return &minimap{
+		img:   ebiten.NewImage(w, h),
+		buf:   make([]uint32, w*h),
+		ants:  make([]int, w*h),
+		w:     w,
+		h:     h,
+		scale: scale,
+	}
+}
+
+// pixel returns the minimap pixel the cell at (x, y) falls in.
+func (m *minimap) pixel(x, y int) int {
+	px, py := int(float64(x)/m.scale), int(float64(y)/m.scale)
+	if px >= m.w {
+		px = m.w - 1
+	}
+	if py >= m.h {
+		py = m.h - 1
+	}
+	return px + py*m.w
+}
+
+// minimapRank ranks what a minimap pixel can show, so the most important
+// thing in its block wins, and returns its colour.
+func minimapRank(g *gridspot) (int, uint32) {
+	switch {
+	case g.Home:
+		return 3, 0xFF3333FF
+	case g.Food > 0:
+		return 2, 0xFF33FF33
+	case g.Wall:
+		return 1, 0xFF333333
+	}
+	return 0, minimapBackground
+}
+
+// update redraws the minimap from the field and ants.
+func (m *minimap) update(as *AntScene) {
+	ranks := make([]int, len(m.buf))
+	for i := range m.buf {
+		m.buf[i] = minimapBackground
+		m.ants[i] = 0
+	}
+	f := as.field
+	for y := 0; y < f.height; y++ {
+		for x := 0; x < f.width; x++ {
+			p := m.pixel(x, y)
+			if r, c := minimapRank(f.Get(x, y)); r > ranks[p] {
+				ranks[p] = r
+				m.buf[p] = c
+			}
+		}
+	}
+	for _, p := range as.ants.pos {
+		m.ants[m.pixel(p.x, p.y)]++
+	}
+
+	// A block is shown as fully ant coloured once one in eight of its cells
+	// has an ant.
+	full := m.scale * m.scale / 8
+	ar, ag, ab := uint32(antColor.R), uint32(antColor.G), uint32(antColor.B)
+	for i, n := range m.ants {
+		if n == 0 {
+			continue
+		}
+		t := uint32(255)
+		if float64(n) < full {
+			t = uint32(float64(n) / full * 255)
+		}
+		c := m.buf[i]
+		r := ((c&0xFF)*(255-t) + ar*t) / 255
+		g := ((c>>8&0xFF)*(255-t) + ag*t) / 255
+		b := ((c>>16&0xFF)*(255-t) + ab*t) / 255
+		m.buf[i] = 0xFF000000 | b<<16 | g<<8 | r
+	}
+
+	m.img.WritePixels(pixelBytes(m.buf))
+}
+
+// bounds returns where the minimap is drawn on a screen of the size given.
+func (m *minimap) bounds(screenW, screenH int) rect {
+	x1, y1 := screenW-minimapMargin, screenH-minimapMargin
+	return rect{x1 - m.w, y1 - m.h, x1, y1}
+}
+
+// worldAt returns the cell under the screen position (sx, sy), and whether
+// it's on the minimap.
+func (m *minimap) worldAt(screenW, screenH, sx, sy int) (point, bool) {
+	b := m.bounds(screenW, screenH)
+	if !(point{sx, sy}).Within(b.x0, b.y0, b.x1-b.x0, b.y1-b.y0) {
+		return point{}, false
+	}
+	return point{int(float64(sx-b.x0) * m.scale), int(float64(sy-b.y0) * m.scale)}, true
+}
+
+// draw draws the minimap onto screen with the view outlined, redrawing the
+// minimap every minimapEvery frames.
+func (m *minimap) draw(as *AntScene, screen *ebiten.Image) {
+	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+	if m.frames%minimapEvery == 0 {
+		m.update(as)
+	}
+	m.frames++
+	b := m.bounds(sw, sh)
+	var dio ebiten.DrawImageOptions
+	dio.GeoM.Translate(float64(b.x0), float64(b.y0))
+	screen.DrawImage(m.img, &dio)
+
+	v := as.viewRect(sw, sh)
+	c0, c1 := as.worldCell(v.x0, v.y0), as.worldCell(v.x1, v.y1)
+	x0, y0 := float64(c0.x)/m.scale, float64(c0.y)/m.scale
+	x1, y1 := math.Min(float64(c1.x)/m.scale, float64(m.w)), math.Min(float64(c1.y)/m.scale, float64(m.h))
+	vector.StrokeRect(screen, float32(float64(b.x0)+x0), float32(float64(b.y0)+y0),
+		float32(x1-x0), float32(y1-y0), 1, color.White, false)
+}
+
+// rect is the rectangle [x0, x1) by [y0, y1).
+type rect struct {
+	x0, y0, x1, y1 int
+}
+
+// viewRect returns the part of the world image on a screen of the size given.
+func (as *AntScene) viewRect(screenW, screenH int) rect {
+	return rect{as.view.x, as.view.y, as.view.x + screenW, as.view.y + screenH}
+}
+
+// setView moves the top left of the view to p, keeping the screen within the
+// world where it fits.
+func (as *AntScene) setView(p point, screenW, screenH int) {
+	clamp := func(v, size, screen int) int {
+		if v > size-screen {
+			v = size - screen
+		}
+		if v < 0 {
+			v = 0
+		}
+		return v
+	}
+	w, h := as.worldSize()
+	as.view = point{clamp(p.x, w, screenW), clamp(p.y, h, screenH)}
+}
+
+// cursor returns the cell under the mouse.
+func (as *AntScene) cursor() (int, int) {
+	mx, my := ebiten.CursorPosition()
+	c := as.worldCell(mx+as.view.x, my+as.view.y)
+	return c.x, c.y
+}
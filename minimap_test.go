@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+func TestMinimap(t *testing.T) {
+	as := testScene(t, 800, 400)
+	m := newMinimap(800, 400)
+	if m.w != minimapSize || m.h != minimapSize/2 || m.scale != 4 {
+		t.Fatalf("Expected a %dx%d minimap at 4 cells per pixel, got %dx%d at %f", minimapSize, minimapSize/2, m.w, m.h, m.scale)
+	}
+
+	as.field.Get(4, 4).Wall = true
+	as.field.Get(9, 9).Wall = true
+	as.field.Get(10, 10).Home = true
+	as.field.Get(40, 40).Food = 5
+	m.update(as)
+	if c := m.buf[m.pixel(4, 4)]; c != 0xFF333333 {
+		t.Errorf("Expected a wall at (4, 4), got %08X", c)
+	}
+	if c := m.buf[m.pixel(10, 10)]; c != 0xFF3333FF {
+		t.Errorf("Expected the hive to win over the wall in its block, got %08X", c)
+	}
+	if c := m.buf[m.pixel(40, 40)]; c != 0xFF33FF33 {
+		t.Errorf("Expected food at (40, 40), got %08X", c)
+	}
+	if c := m.buf[m.pixel(100, 100)]; c != minimapBackground {
+		t.Errorf("Expected nothing at (100, 100), got %08X", c)
+	}
+
+	b := m.bounds(640, 360)
+	if p, ok := m.worldAt(640, 360, b.x0+50, b.y0+25); !ok || p != (point{200, 100}) {
+		t.Errorf("Expected a click 50, 25 into the minimap to be at (200, 100), got %v, %t", p, ok)
+	}
+	if _, ok := m.worldAt(640, 360, b.x0-1, b.y0); ok {
+		t.Errorf("Expected a click left of the minimap to miss it")
+	}
+	if _, ok := m.worldAt(640, 360, b.x1, b.y0); ok {
+		t.Errorf("Expected a click just right of the minimap to miss it")
+	}
+	if _, ok := m.worldAt(640, 360, b.x0, b.y1); ok {
+		t.Errorf("Expected a click just below the minimap to miss it")
+	}
+	if p, ok := m.worldAt(640, 360, b.x1-1, b.y1-1); !ok || !p.Within(0, 0, 800, 400) {
+		t.Errorf("Expected a click in the minimap's bottom right corner to be in the world, got %v, %t", p, ok)
+	}
+
+	as.setView(point{700, -20}, 640, 360)
+	if as.view != (point{160, 0}) {
+		t.Errorf("Expected the view to be kept within the world at (160, 0), got %v", as.view)
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// distField holds the fewest steps from each cell to the nearest hive cell,
+// going around walls, for the current topology. Cells that can't reach the
+// hive are -1.
+type distField struct {
+	dist      []int32
+	w, h      int
+	wrap, hex bool // Topology the field was worked out for
+	stale     bool // Walls or the hive have been drawn since
+}
+
+// at returns the steps from p to the hive, or -1 if it can't get there.
+func (d *distField) at(p point) int {
+	if !p.Within(0, 0, d.w, d.h) {
+		return -1
+	}
+	return int(d.dist[p.x+p.y*d.w])
+}
+
+// neighbours calls f for every cell one step from p that isn't a wall.
+func (as *AntScene) neighbours(p point, f func(q point)) {
+	for i := 0; i < as.dirCount(); i++ {
+		d := direction(i)
+		if as.hexGrid() {
+			d = hexDirs[i]
+		}
+		q, ok := as.cell(as.step(p, d))
+		if ok && !as.field.Get(q.x, q.y).Wall {
+			f(q)
+		}
+	}
+}
+
+// nestDistances works out the distance field with a breadth first search out
+// from every hive cell at once.
+func (as *AntScene) nestDistances() *distField {
+	f := as.field
+	d := &distField{
+		dist: make([]int32, f.width*f.height),
+		w:    f.width,
+		h:    f.height,
+		wrap: as.st.wrap,
+		hex:  as.hexGrid(),
+	}
+	var queue []point
+	for i := range d.dist {
+		d.dist[i] = -1
+		if g := &f.vals[i]; g.Home && !g.Wall {
+			d.dist[i] = 0
+			queue = append(queue, point{i % f.width, i / f.width})
+		}
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		next := d.dist[p.x+p.y*d.w] + 1
+		as.neighbours(p, func(q point) {
+			if i := q.x + q.y*d.w; d.dist[i] < 0 {
+				d.dist[i] = next
+				queue = append(queue, q)
+			}
+		})
+	}
+	return d
+}
+
+// refreshNestDist works out the distance field again if it's missing, walls
+// or the hive have been drawn since, or it's for another topology. Otherwise
+// the field can't have changed, so it's kept.
+func (as *AntScene) refreshNestDist() {
+	d := as.nestDist
+	if d == nil || d.stale || d.wrap != as.st.wrap || d.hex != as.hexGrid() {
+		as.nestDist = as.nestDistances()
+	}
+}
+
+// gridEdited marks the distance field as out of date. It's only called when
+// walls or the hive actually change, since anything else leaves the distances
+// as they were.
+func (as *AntScene) gridEdited() {
+	if as.nestDist != nil {
+		as.nestDist.stale = true
+	}
+}
+
+// pathFrom returns a shortest way from p to the hive, starting with p, or nil
+// if there isn't one.
+func (d *distField) pathFrom(as *AntScene, p point) []point {
+	n := d.at(p)
+	if n < 0 {
+		return nil
+	}
+	path := []point{p}
+	for ; n > 0; n-- {
+		as.neighbours(p, func(q point) {
+			if d.at(q) == n-1 {
+				p = q
+			}
+		})
+		path = append(path, p)
+	}
+	return path
+}
+
+const (
+	distAlpha       = 0.7        // Opacity of the distance overlay
+	distUnreachable = 0xFF0000C0 // Cells that can't reach the hive
+	wallDist        = -2         // Marks walls in the overlay, which are left clear
+)
+
+// distOverlay draws the distance field over the field, nearest the hive
+// brightest, with cells that can't reach the hive in red.
+type distOverlay struct {
+	field  *Field[int32]
+	img    *ebiten.Image
+	from   *distField // The distance field last copied into field
+	colors colorKey   // The colour settings field was last coloured with
+	max    int32
+}
+
+func newDistOverlay(as *AntScene) (*distOverlay, error) {
+	o := &distOverlay{}
+	f, err := NewField[int32](as.field.width, as.field.height, func(d *int32) uint32 {
+		return o.color(as.st, *d)
+	})
+	if err != nil {
+		return nil, err
+	}
+	o.field = f
+	return o, nil
+}
+
+// color colours a cell d steps from the hive with the palette, or magma if
+// the palette mixes two colours, premultiplied by distAlpha.
+func (o *distOverlay) color(st *GameState, d int32) uint32 {
+	var c uint32
+	switch {
+	case d == wallDist:
+		return 0
+	case d < 0:
+		c = distUnreachable
+	default:
+		p := st.palette
+		if p.mixed() {
+			p = paletteMagma
+		}
+		l := uint8(255)
+		if o.max > 0 {
+			l = uint8(255 - 255*int64(d)/int64(o.max))
+		}
+		c = p.color(l, 0)
+	}
+	var px uint32
+	for ch := 0; ch < 4; ch++ {
+		v := float64(c >> (8 * ch) & 0xFF)
+		px |= uint32(v*distAlpha+0.5) << (8 * ch)
+	}
+	return px
+}
+
+// refresh copies the distance field into field when it's been worked out
+// again, and recolours field when it or the colour settings have changed.
+func (o *distOverlay) refresh(as *AntScene) {
+	d, k := as.nestDist, as.st.colorKey()
+	if o.from == d && o.colors == k {
+		return
+	}
+	if o.from != d {
+		o.max = 0
+		for i, n := range d.dist {
+			if as.field.vals[i].Wall {
+				n = wallDist
+			}
+			o.field.vals[i] = n
+			if n > o.max {
+				o.max = n
+			}
+		}
+	}
+	o.from, o.colors = d, k
+	o.field.UpdateAll()
+}
+
+// draw draws the overlay and the shortest way home from the cursor.
+func (o *distOverlay) draw(as *AntScene, world *ebiten.Image) error {
+	o.refresh(as)
+	d := as.nestDist
+	ww, wh := as.worldSize()
+	o.img = sizedImage(o.img, ww, wh)
+	var err error
+	if as.hexGrid() {
+		o.img.Clear()
+		err = o.field.RenderHex(o.img)
+	} else {
+		err = o.field.Render(o.img)
+	}
+	if err != nil {
+		return err
+	}
+	world.DrawImage(o.img, nil)
+
+	mx, my := as.cursor()
+	path := d.pathFrom(as, point{mx, my})
+	for i := 1; i < len(path); i++ {
+		p0, p1 := path[i-1], path[i]
+		if absi(p1.x-p0.x) > 1 || absi(p1.y-p0.y) > 1 {
+			// The way goes over an edge.
+			continue
+		}
+		x0, y0 := as.worldPos(p0)
+		x1, y1 := as.worldPos(p1)
+		vector.StrokeLine(world, float32(x0), float32(y0), float32(x1), float32(y1), 1, color.White, false)
+	}
+	return nil
+}
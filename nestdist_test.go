@@ -0,0 +1,144 @@
+package main
+
+import "testing"
+
+func TestNestDistances(t *testing.T) {
+	as := testScene(t, 10, 10)
+	as.field.Get(0, 0).Home = true
+	// A wall down the middle with a gap at the bottom.
+	for y := 0; y < 9; y++ {
+		as.field.Get(5, y).Wall = true
+	}
+	d := as.nestDistances()
+	if n := d.at(point{4, 4}); n != 4 {
+		t.Errorf("Expected (4, 4) to be 4 steps from the hive, got %d", n)
+	}
+	if n := d.at(point{9, 0}); n != 18 {
+		t.Errorf("Expected (9, 0) to be 18 steps from the hive around the wall, got %d", n)
+	}
+	if n := d.at(point{5, 0}); n != -1 {
+		t.Errorf("Expected a wall to be unreachable, got %d", n)
+	}
+
+	as.st.wrap = true
+	if n := as.nestDistances().at(point{9, 0}); n != 1 {
+		t.Errorf("Expected (9, 0) to be next to the hive over the edge, got %d", n)
+	}
+}
+
+func TestRefreshNestDist(t *testing.T) {
+	as := testScene(t, 10, 10)
+	as.field.Get(0, 0).Home = true
+	as.refreshNestDist()
+	d := as.nestDist
+	for i := 0; i < 1000; i++ {
+		as.frame++
+		as.refreshNestDist()
+	}
+	if as.nestDist != d {
+		t.Errorf("Expected the distance field to be kept while nothing changed")
+	}
+
+	as.field.Get(1, 0).Wall = true
+	as.gridEdited()
+	as.refreshNestDist()
+	if as.nestDist == d || as.nestDist.at(point{1, 0}) != -1 {
+		t.Errorf("Expected the distance field to be worked out again after an edit")
+	}
+	d = as.nestDist
+	as.st.wrap = true
+	as.refreshNestDist()
+	if as.nestDist == d || as.nestDist.at(point{9, 0}) != 1 {
+		t.Errorf("Expected the distance field to be worked out again for the new topology")
+	}
+}
+
+func TestPathEfficiency(t *testing.T) {
+	as := testScene(t, 20, 20)
+	for x := 0; x < 10; x++ {
+		for y := 0; y < 10; y++ {
+			as.field.Get(x, y).Home = true
+		}
+	}
+	as.ants.add(Ant{pos: point{5, 5}, food: 1, life: 100, pickupAt: point{15, 5}, homeSteps: 11})
+	as.Step()
+	if len(as.trips.efficiency.vals) != 1 {
+		t.Fatalf("Expected one way home, got %v", as.trips.efficiency.vals)
+	}
+	// (15, 5) is 6 steps from the hive, and the ant took 11 or 12 depending
+	// on whether it moved this tick.
+	if e := as.trips.efficiency.vals[0]; e != 6.0/11 && e != 6.0/12 {
+		t.Errorf("Expected an efficiency of 6/11 or 6/12, got %f", e)
+	}
+
+	as.trips.returned(-1, 10)
+	as.trips.returned(5, 0)
+	if len(as.trips.efficiency.vals) != 1 {
+		t.Errorf("Expected unreachable and zero length ways home to be skipped, got %v", as.trips.efficiency.vals)
+	}
+}
+
+func TestPathFrom(t *testing.T) {
+	as := testScene(t, 10, 10)
+	as.field.Get(0, 0).Home = true
+	for y := 0; y < 9; y++ {
+		as.field.Get(5, y).Wall = true
+	}
+	d := as.nestDistances()
+	path := d.pathFrom(as, point{9, 0})
+	if len(path) != 19 || path[0] != (point{9, 0}) || path[18] != (point{0, 0}) {
+		t.Fatalf("Expected 19 cells from (9, 0) to the hive, got %v", path)
+	}
+	for i, p := range path {
+		if as.field.Get(p.x, p.y).Wall {
+			t.Errorf("Expected the way home to avoid walls, but went through %v", p)
+		}
+		if n := d.at(p); n != 18-i {
+			t.Errorf("Expected %v to be %d steps from the hive, got %d", p, 18-i, n)
+		}
+	}
+	if path := d.pathFrom(as, point{5, 0}); path != nil {
+		t.Errorf("Expected no way home from a wall, got %v", path)
+	}
+}
+
+func TestDistOverlayColor(t *testing.T) {
+	as := testScene(t, 10, 10)
+	o, err := newDistOverlay(as)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.max = 10
+	if c := o.color(as.st, wallDist); c != 0 {
+		t.Errorf("Expected walls to be clear, got %08x", c)
+	}
+	if c := o.color(as.st, -1); c>>24 != uint32(255*distAlpha+0.5) || c&0xFF == 0 {
+		t.Errorf("Expected unreachable cells to be see through red, got %08x", c)
+	}
+	near, far := o.color(as.st, 0), o.color(as.st, 10)
+	lum := func(c uint32) uint32 { return c&0xFF + c>>8&0xFF + c>>16&0xFF }
+	if lum(near) <= lum(far) {
+		t.Errorf("Expected the hive to be brighter than the farthest cell, got %08x and %08x", near, far)
+	}
+}
+
+func TestDistOverlayRefresh(t *testing.T) {
+	as := testScene(t, 10, 10)
+	as.field.Get(0, 0).Home = true
+	as.refreshNestDist()
+	o, err := newDistOverlay(as)
+	if err != nil {
+		t.Fatal(err)
+	}
+	as.st.palette = paletteViridis
+	o.refresh(as)
+	before := o.field.renderbuf[5]
+	as.st.palette = paletteMagma
+	o.refresh(as)
+	if o.field.renderbuf[5] == before {
+		t.Errorf("Expected the overlay to be recoloured when the palette changes, still %08x", before)
+	}
+	if want := o.color(as.st, o.field.vals[5]); o.field.renderbuf[5] != want {
+		t.Errorf("Expected %08x in the new palette, got %08x", want, o.field.renderbuf[5])
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type optAxis struct {
+	p        *param
+	min, max int
+}
+
+// parseSearchSpace parses the parameters to optimise, like
+// "fadedivisor=100:2000;sight=1:50;followwalls". A parameter without bounds
+// is searched over its whole range.
+func parseSearchSpace(spec string) ([]optAxis, error) {
+	var axes []optAxis
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, bounds, hasBounds := strings.Cut(part, "=")
+		p, err := findParam(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		ax := optAxis{p: p, min: p.min, max: p.max}
+		if hasBounds {
+			lo, hi, ok := strings.Cut(bounds, ":")
+			if !ok {
+				return nil, fmt.Errorf("bad bounds %q for %s, expected min:max", bounds, p.name)
+			}
+			ax.min, err = strconv.Atoi(strings.TrimSpace(lo))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", p.name, err)
+			}
+			ax.max, err = strconv.Atoi(strings.TrimSpace(hi))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", p.name, err)
+			}
+			if ax.min < p.min || ax.max > p.max || ax.min > ax.max {
+				return nil, fmt.Errorf("%s bounds %d:%d must be within [%d, %d]", p.name, ax.min, ax.max, p.min, p.max)
+			}
+		}
+		axes = append(axes, ax)
+	}
+	if len(axes) == 0 {
+		return nil, fmt.Errorf("nothing to optimise")
+	}
+	return axes, nil
+}
+
+// objectives are the quantities the optimiser can maximise.
+var objectives = map[string]func(s runSummary, ticks int) float64{
+	"delivered": func(s runSummary, ticks int) float64 { return s.delivered / float64(ticks) },
+	"ants":      func(s runSummary, ticks int) float64 { return s.meanAnts },
+	"peak":      func(s runSummary, ticks int) float64 { return s.peakAnts },
+}
+
+type optOpts struct {
+	runOpts
+	axes      []optAxis
+	objective string
+	method    string // "hill" or "random"
+	evals     int    // Number of configurations to try
+
+	// progress, if set, is called with the best score so far after the
+	// starting configuration and after each batch.
+	progress func(evals int, best float64)
+}
+
+type optimizer struct {
+	optOpts
+	rng *rand.Rand
+}
+
+// runOptimize searches the axes for the configuration of base that maximises
+// the objective, and returns the best one found with its score. Every
+// configuration is run with the same seeds, so they're compared fairly.
+func runOptimize(base GameState, o optOpts) (GameState, float64, error) {
+	obj, ok := objectives[o.objective]
+	if !ok {
+		return base, 0, fmt.Errorf("no objective %q, expected delivered, ants or peak", o.objective)
+	}
+	if o.method != "hill" && o.method != "random" {
+		return base, 0, fmt.Errorf("no method %q, expected hill or random", o.method)
+	}
+	if o.ticks <= 0 {
+		return base, 0, fmt.Errorf("optimising needs a positive number of ticks")
+	}
+	if o.jobs < 1 {
+		o.jobs = 1
+	}
+	opt := &optimizer{optOpts: o, rng: rand.New(rand.NewSource(o.seed))}
+
+	evaluate := func(configs []GameState) ([]float64, error) {
+		results, err := runAll(configs, o.runOpts, "optimise")
+		if err != nil {
+			return nil, err
+		}
+		scores := make([]float64, len(configs))
+		for i := range results {
+			scores[i] = obj(summarise(results[i]), o.ticks)
+		}
+		return scores, nil
+	}
+
+	best := opt.clamp(base)
+	scores, err := evaluate([]GameState{best})
+	if err != nil {
+		return base, 0, err
+	}
+	bestScore := scores[0]
+	current, currentScore := best, bestScore
+	fmt.Fprintf(os.Stderr, "optimise: starting score %f\n", bestScore)
+	if o.progress != nil {
+		o.progress(1, bestScore)
+	}
+
+	// Each axis starts out stepping a quarter of its range, and the steps
+	// halve whenever a batch of neighbours finds nothing better.
+	steps := make([]int, len(o.axes))
+	for i, ax := range o.axes {
+		steps[i] = (ax.max-ax.min)/4 + 1
+	}
+
+	batch := o.jobs
+	if batch < 2*len(o.axes) {
+		batch = 2 * len(o.axes)
+	}
+	for evals := 1; evals < o.evals; {
+		n := batch
+		if evals+n > o.evals {
+			n = o.evals - evals
+		}
+		cands := make([]GameState, n)
+		for i := range cands {
+			if o.method == "random" {
+				cands[i] = opt.random(base)
+			} else {
+				cands[i] = opt.neighbour(current, steps)
+			}
+		}
+		scores, err := evaluate(cands)
+		if err != nil {
+			return best, bestScore, err
+		}
+		evals += n
+
+		improved := false
+		for i := range cands {
+			if scores[i] > currentScore {
+				current, currentScore = cands[i], scores[i]
+				improved = true
+			}
+		}
+		if !improved {
+			for i := range steps {
+				if steps[i] > 1 {
+					steps[i] /= 2
+				}
+			}
+		}
+		if currentScore > bestScore {
+			best, bestScore = current, currentScore
+		}
+		fmt.Fprintf(os.Stderr, "optimise: %d/%d configurations tried, best score %f\n", evals, o.evals, bestScore)
+		if o.progress != nil {
+			o.progress(evals, bestScore)
+		}
+	}
+	return best, bestScore, nil
+}
+
+func (o *optimizer) clamp(st GameState) GameState {
+	for _, ax := range o.axes {
+		v := ax.p.get(&st)
+		if v < ax.min {
+			v = ax.min
+		}
+		if v > ax.max {
+			v = ax.max
+		}
+		ax.p.set(&st, v)
+	}
+	return st
+}
+
+func (o *optimizer) random(st GameState) GameState {
+	for _, ax := range o.axes {
+		ax.p.set(&st, ax.min+o.rng.Intn(ax.max-ax.min+1))
+	}
+	return st
+}
+
+// neighbour moves a random selection of at least one axis by up to its step.
+func (o *optimizer) neighbour(st GameState, steps []int) GameState {
+	moved := false
+	for !moved {
+		for i, ax := range o.axes {
+			if o.rng.Intn(2) == 0 {
+				continue
+			}
+			d := o.rng.Intn(2*steps[i]+1) - steps[i]
+			if d == 0 {
+				continue
+			}
+			ax.p.set(&st, ax.p.get(&st)+d)
+			moved = true
+		}
+	}
+	return o.clamp(st)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestParseSearchSpace(t *testing.T) {
+	for _, c := range []struct {
+		spec string
+		want []optAxis // Only the names and bounds are compared
+	}{
+		{"sight", []optAxis{{p: &param{name: "sight"}, min: 1, max: 200}}},
+		{"sight=5:20", []optAxis{{p: &param{name: "sight"}, min: 5, max: 20}}},
+		{" fadedivisor = 100:2000 ; followwalls ;", []optAxis{
+			{p: &param{name: "fadedivisor"}, min: 100, max: 2000},
+			{p: &param{name: "followwalls"}, min: 0, max: 1},
+		}},
+	} {
+		axes, err := parseSearchSpace(c.spec)
+		if err != nil {
+			t.Errorf("%q: %v", c.spec, err)
+			continue
+		}
+		if len(axes) != len(c.want) {
+			t.Errorf("%q: expected %d axes, got %d", c.spec, len(c.want), len(axes))
+			continue
+		}
+		for i, ax := range axes {
+			w := c.want[i]
+			if ax.p.name != w.p.name || ax.min != w.min || ax.max != w.max {
+				t.Errorf("%q: expected %s=%d:%d, got %s=%d:%d", c.spec, w.p.name, w.min, w.max, ax.p.name, ax.min, ax.max)
+			}
+		}
+	}
+
+	for _, bad := range []string{"", ";", "nosuchparam", "sight=5", "sight=a:5", "sight=5:b", "sight=0:5", "sight=5:201", "sight=20:5"} {
+		if _, err := parseSearchSpace(bad); err == nil {
+			t.Errorf("Expected an error parsing %q", bad)
+		}
+	}
+}
+
+func TestOptimizerBounds(t *testing.T) {
+	axes, err := parseSearchSpace("sight=5:20;followwalls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := &optimizer{optOpts: optOpts{axes: axes}, rng: rand.New(rand.NewSource(1))}
+
+	st := NewGameState(10, 10)
+	st.sight = 100
+	if st = o.clamp(st); st.sight != 20 {
+		t.Errorf("Expected sight 100 to be clamped to 20, got %d", st.sight)
+	}
+	st.sight = 1
+	if st = o.clamp(st); st.sight != 5 {
+		t.Errorf("Expected sight 1 to be clamped to 5, got %d", st.sight)
+	}
+
+	steps := []int{100, 1}
+	for i := 0; i < 100; i++ {
+		n := o.neighbour(st, steps)
+		if n.sight < 5 || n.sight > 20 {
+			t.Fatalf("Expected neighbours to keep sight within 5:20, got %d", n.sight)
+		}
+		st = n
+	}
+}
+
+func TestRunOptimize(t *testing.T) {
+	axes, err := parseSearchSpace("sight=1:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bests []float64
+	o := optOpts{
+		runOpts:   runOpts{ticks: 100, seeds: 1, seed: 3, homelife: 1000000, jobs: 1},
+		axes:      axes,
+		objective: "ants",
+		method:    "hill",
+		evals:     5,
+		progress:  func(_ int, best float64) { bests = append(bests, best) },
+	}
+	_, score, err := runOptimize(testState(), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bests) < 2 {
+		t.Fatalf("Expected progress after the start and each batch, got %v", bests)
+	}
+	for i := 1; i < len(bests); i++ {
+		if bests[i] < bests[i-1] {
+			t.Errorf("Expected the best score never to get worse, got %v", bests)
+		}
+	}
+	if score != bests[len(bests)-1] {
+		t.Errorf("Expected the returned score %f to be the last best %f", score, bests[len(bests)-1])
+	}
+
+	o.objective = "nosuch"
+	if _, _, err := runOptimize(testState(), o); err == nil {
+		t.Errorf("Expected an unknown objective to be an error")
+	}
+}
@@ -22,7 +22,7 @@ type OptScene struct {
 }
 
 func (s *OptScene) Init(g *Game[GameState], st *GameState) error {
-	s.blank = ebiten.NewImage(st.width, st.height)
+	s.blank = ebiten.NewImage(g.width, g.height)
 	s.blank.Fill(color.RGBA{A: 0xbf})
 	// fmt.Printf("Drawing Black\n")
 	// draw.Draw(
@@ -101,6 +101,72 @@ func makeTexts(st *GameState) []opt {
 			left:  func(_ int) { st.renderRed = !st.renderRed },
 			right: func(_ int) { st.renderRed = !st.renderRed },
 		},
+		{
+			name:  "Pheromone Scale",
+			value: st.pherScale.String(),
+			left:  func(_ int) { st.pherScale = (st.pherScale + endScale - 1) % endScale },
+			right: func(_ int) { st.pherScale = (st.pherScale + 1) % endScale },
+		},
+		{
+			name:  "Pheromone Palette",
+			value: st.palette.String(),
+			left:  func(_ int) { st.palette = (st.palette + endPalette - 1) % endPalette },
+			right: func(_ int) { st.palette = (st.palette + 1) % endPalette },
+		},
+		{
+			name:  "Minimap (N)",
+			value: fmt.Sprintf("%t", st.minimap),
+			left:  func(_ int) { st.minimap = !st.minimap },
+			right: func(_ int) { st.minimap = !st.minimap },
+		},
+		{
+			name:  "Nest Distance (H)",
+			value: fmt.Sprintf("%t", st.distOverlay),
+			left:  func(_ int) { st.distOverlay = !st.distOverlay },
+			right: func(_ int) { st.distOverlay = !st.distOverlay },
+		},
+		{
+			name:  "Trail Fade (ticks)",
+			value: fmt.Sprintf("%d", st.trailFade),
+			left:  withProgressiveDuration(func(x int) { st.trailFade -= x * 10 }),
+			right: withProgressiveDuration(func(x int) { st.trailFade += x * 10 }),
+		},
+		{
+			name:  "Heatmap",
+			value: st.heatmap.String(),
+			left:  func(_ int) { st.heatmap = (st.heatmap + endLayer - 1) % endLayer },
+			right: func(_ int) { st.heatmap = (st.heatmap + 1) % endLayer },
+		},
+		{
+			name:  "Heatmap Window (ticks)",
+			value: fmt.Sprintf("%d", st.heatWindow),
+			left:  withProgressiveDuration(func(x int) { st.heatWindow -= x * 10 }),
+			right: withProgressiveDuration(func(x int) { st.heatWindow += x * 10 }),
+		},
+		{
+			name:  "GPU Rendering",
+			value: fmt.Sprintf("%t", st.gpuRender),
+			left:  func(_ int) { st.gpuRender = !st.gpuRender },
+			right: func(_ int) { st.gpuRender = !st.gpuRender },
+		},
+		{
+			name:  "Pheromone Threshold (GPU)",
+			value: fmt.Sprintf("%d", st.threshold),
+			left:  withProgressiveDuration(func(x int) { st.threshold -= x }),
+			right: withProgressiveDuration(func(x int) { st.threshold += x }),
+		},
+		{
+			name:  "Cell Info Under Cursor (I)",
+			value: fmt.Sprintf("%t", st.renderCell),
+			left:  func(_ int) { st.renderCell = !st.renderCell },
+			right: func(_ int) { st.renderCell = !st.renderCell },
+		},
+		{
+			name:  "Sensing Rays (D)",
+			value: fmt.Sprintf("%t", st.renderSense),
+			left:  func(_ int) { st.renderSense = !st.renderSense },
+			right: func(_ int) { st.renderSense = !st.renderSense },
+		},
 		{
 			name:  "Parallel Execution (X)",
 			value: fmt.Sprintf("%t", st.parallel),
@@ -113,6 +179,36 @@ func makeTexts(st *GameState) []opt {
 			left:  func(_ int) { st.followWalls = !st.followWalls },
 			right: func(_ int) { st.followWalls = !st.followWalls },
 		},
+		{
+			name:  "Evolve Genomes (E)",
+			value: fmt.Sprintf("%t", st.evolve),
+			left:  func(_ int) { st.evolve = !st.evolve },
+			right: func(_ int) { st.evolve = !st.evolve },
+		},
+		{
+			name:  "Continuous Movement",
+			value: fmt.Sprintf("%t", st.continuous),
+			left:  func(_ int) { st.continuous = !st.continuous },
+			right: func(_ int) { st.continuous = !st.continuous },
+		},
+		{
+			name:  "Continuous Speed (% of a cell/tick)",
+			value: fmt.Sprintf("%d", st.speed),
+			left:  withProgressiveDuration(func(x int) { st.speed -= x }),
+			right: withProgressiveDuration(func(x int) { st.speed += x }),
+		},
+		{
+			name:  "Wrap Around Edges",
+			value: fmt.Sprintf("%t", st.wrap),
+			left:  func(_ int) { st.wrap = !st.wrap },
+			right: func(_ int) { st.wrap = !st.wrap },
+		},
+		{
+			name:  "Hexagonal Grid",
+			value: fmt.Sprintf("%t", st.hex),
+			left:  func(_ int) { st.hex = !st.hex },
+			right: func(_ int) { st.hex = !st.hex },
+		},
 		{
 			name:  "Antisocial",
 			value: fmt.Sprintf("%t", st.antisocial),
@@ -126,6 +222,18 @@ func makeTexts(st *GameState) []opt {
 			left:  withProgressiveDuration(func(x int) { st.antlife -= x }),
 			right: withProgressiveDuration(func(x int) { st.antlife += x }),
 		},
+		{
+			name:  "Sensing Model",
+			value: st.sensing.String(),
+			left:  func(_ int) { st.sensing = (st.sensing + endSense - 1) % endSense },
+			right: func(_ int) { st.sensing = (st.sensing + 1) % endSense },
+		},
+		{
+			name:  "Sensing Saturation (0 is off)",
+			value: fmt.Sprintf("%d", st.saturate),
+			left:  withProgressiveDuration(func(x int) { st.saturate -= x * 10 }),
+			right: withProgressiveDuration(func(x int) { st.saturate += x * 10 }),
+		},
 		{
 			name:  "Ant Sight Distance",
 			value: fmt.Sprintf("%d", st.sight),
@@ -162,6 +270,30 @@ func makeTexts(st *GameState) []opt {
 			left:  withProgressiveDuration(func(x int) { st.drawradius -= x }),
 			right: withProgressiveDuration(func(x int) { st.drawradius += x }),
 		},
+		{
+			name:  "Forager Ratio",
+			value: fmt.Sprintf("%d", st.castes[forager].ratio),
+			left:  withProgressiveDuration(func(x int) { st.castes[forager].ratio -= x }),
+			right: withProgressiveDuration(func(x int) { st.castes[forager].ratio += x }),
+		},
+		{
+			name:  "Scout Ratio (long sight, wanders)",
+			value: fmt.Sprintf("%d", st.castes[scout].ratio),
+			left:  withProgressiveDuration(func(x int) { st.castes[scout].ratio -= x }),
+			right: withProgressiveDuration(func(x int) { st.castes[scout].ratio += x }),
+		},
+		{
+			name:  "Carrier Ratio (carries more, slow)",
+			value: fmt.Sprintf("%d", st.castes[carrier].ratio),
+			left:  withProgressiveDuration(func(x int) { st.castes[carrier].ratio -= x }),
+			right: withProgressiveDuration(func(x int) { st.castes[carrier].ratio += x }),
+		},
+		{
+			name:  "Clip Length (frames)",
+			value: fmt.Sprintf("%d", st.clipframes),
+			left:  withProgressiveDuration(func(x int) { st.clipframes -= x }),
+			right: withProgressiveDuration(func(x int) { st.clipframes += x }),
+		},
 		{
 			name:  "Pheromone Resilience",
 			value: fmt.Sprintf("%d", st.fadedivisor),
@@ -201,7 +333,7 @@ func (s *OptScene) Draw(g *Game[GameState], st *GameState, screen *ebiten.Image)
 	for oi := range s.opts {
 		if oi == s.index {
 			c = color.RGBA{R: 0x55, G: 0xFF, B: 0xff, A: 0xFF}
-			doLine(0, y+2, st.width/2, y+2, func(x, y int) {
+			doLine(0, y+2, screen.Bounds().Dx()/2, y+2, func(x, y int) {
 				screen.Set(x, y, c)
 			})
 		}
@@ -228,6 +360,8 @@ func (s *OptScene) Draw(g *Game[GameState], st *GameState, screen *ebiten.Image)
 		"P: Toggle Pheromone Rendering",
 		"G: Toggle Green Pheromone Rendering",
 		"R: Toggle Red Pheromone Rendering",
+		"I: Toggle cell info under the cursor",
+		"D: Toggle sensing rays (of the inspected ant, or a sample)",
 		"X: Toggle Parallel Execution",
 		"W: Toggle Wall Following",
 		"A: Reset Ants to (0,0)",
@@ -235,10 +369,20 @@ func (s *OptScene) Draw(g *Game[GameState], st *GameState, screen *ebiten.Image)
 		"L: Load the saved grid",
 		"C: Clear the grid",
 		"F: Fill the grid with wall",
+		"E: Toggle genome evolution",
+		"T: Save genome statistics",
+		"O: Save a screenshot",
+		"V: Start/stop recording a GIF clip",
 		"M: This menu",
 		"Space: Pause",
 		"Up/Down: Increase and decrease brush radius",
-		"Left/Right: Change the current brush",
+		"Left/Right: Change the current brush (Inspect: click an ant to follow it)",
+		"Shift + Arrows: Pan the view",
+		"N: Toggle the minimap (click it to jump there)",
+		"H: Toggle the distance to the hive, and the shortest way home from the cursor",
+		"K: Tag 10 random ants to trace their trails (Tag brush: click an ant)",
+		"U: Untag all ants and forget their trails",
+		"J: Save the traced trails as CSV",
 	}
 
 	y += step
@@ -287,5 +431,41 @@ func (s *OptScene) Update(g *Game[GameState], state *GameState) error {
 		state.fadedivisor = 1
 	}
 
+	if state.speed < 1 {
+		state.speed = 1
+		s.opts = makeTexts(state)
+	} else if state.speed > 100 {
+		state.speed = 100
+		s.opts = makeTexts(state)
+	}
+
+	if state.saturate < 0 {
+		state.saturate = 0
+		s.opts = makeTexts(state)
+	}
+
+	// The field can't be resized here, so a hex grid with an odd height
+	// can't wrap.
+	if state.hex && state.wrap && state.height%2 != 0 {
+		state.wrap = false
+		s.opts = makeTexts(state)
+	}
+
+	if state.heatWindow < 1 {
+		state.heatWindow = 1
+		s.opts = makeTexts(state)
+	}
+
+	if state.clipframes <= 0 {
+		state.clipframes = 1
+	}
+
+	for c := range state.castes {
+		if state.castes[c].ratio < 0 {
+			state.castes[c].ratio = 0
+			s.opts = makeTexts(state)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestParallelMatchesSerial(t *testing.T) {
+	defer func(w int) { workers = w }(workers)
+	workers = 4
+
+	run := func(parallel bool) *AntScene {
+		st := testState()
+		st.antlife = 3000
+		st.parallel = parallel
+		st.renderPher = false
+		as, err := newHeadlessScene(&st, 1000000, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		as.rng = rand.New(rand.NewSource(11))
+		for i := range as.field.vals {
+			as.field.vals[i].Wall = false
+		}
+		for x := 100; x < 120; x++ {
+			for y := 20; y < 40; y++ {
+				as.field.Get(x, y).Food = 50
+			}
+		}
+		for i := 0; i < 600; i++ {
+			as.Step()
+		}
+		as.Close()
+		return as
+	}
+	serial, parallel := run(false), run(true)
+
+	if serial.delivered == 0 {
+		t.Errorf("Expected some food to be delivered")
+	}
+	if serial.delivered != parallel.delivered || serial.homelife != parallel.homelife {
+		t.Errorf("Serial run delivered %d with hive life %d, but parallel run delivered %d with hive life %d",
+			serial.delivered, serial.homelife, parallel.delivered, parallel.homelife)
+	}
+	if serial.ants.len() != parallel.ants.len() {
+		t.Fatalf("Serial run has %d ants, but parallel run has %d", serial.ants.len(), parallel.ants.len())
+	}
+	for i := 0; i < serial.ants.len(); i++ {
+		if a, b := serial.ants.get(i), parallel.ants.get(i); a != b {
+			t.Fatalf("Ant %d differs: serial %+v, parallel %+v", i, a, b)
+		}
+	}
+	for i := range serial.field.vals {
+		if serial.field.vals[i] != parallel.field.vals[i] {
+			t.Fatalf("Cell %d differs: serial %+v, parallel %+v", i, serial.field.vals[i], parallel.field.vals[i])
+		}
+	}
+}
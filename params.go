@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// param is a GameState parameter that can be set by name, for sweeps and
+// config files. Booleans are 0 or 1.
+type param struct {
+	name     string
+	min, max int
+	get      func(*GameState) int
+	set      func(*GameState, int)
+}
+
+func boolParam(name string, f func(*GameState) *bool) param {
+	return param{name, 0, 1,
+		func(st *GameState) int {
+			if *f(st) {
+				return 1
+			}
+			return 0
+		},
+		func(st *GameState, v int) { *f(st) = v != 0 },
+	}
+}
+
+func intParam(name string, min, max int, f func(*GameState) *int) param {
+	return param{name, min, max,
+		func(st *GameState) int { return *f(st) },
+		func(st *GameState, v int) { *f(st) = v },
+	}
+}
+
+var params = []param{
+	intParam("antlife", 1, 1000000, func(st *GameState) *int { return &st.antlife }),
+	intParam("foodcount", 1, 100000, func(st *GameState) *int { return &st.foodcount }),
+	intParam("foodlife", 0, 1000000, func(st *GameState) *int { return &st.foodlife }),
+	intParam("spawnparam", 1, 1000, func(st *GameState) *int { return &st.spawnparam }),
+	intParam("maxants", 0, 1000000, func(st *GameState) *int { return &st.maxants }),
+	intParam("fadedivisor", 1, 100000, func(st *GameState) *int { return &st.fadedivisor }),
+	intParam("sight", 1, 200, func(st *GameState) *int { return &st.sight }),
+	boolParam("followwalls", func(st *GameState) *bool { return &st.followWalls }),
+	boolParam("antisocial", func(st *GameState) *bool { return &st.antisocial }),
+	boolParam("evolve", func(st *GameState) *bool { return &st.evolve }),
+	intParam("sensing", 0, int(endSense)-1, func(st *GameState) *int { return (*int)(&st.sensing) }),
+	boolParam("continuous", func(st *GameState) *bool { return &st.continuous }),
+	boolParam("hex", func(st *GameState) *bool { return &st.hex }),
+	boolParam("wrap", func(st *GameState) *bool { return &st.wrap }),
+	intParam("speed", 1, 100, func(st *GameState) *int { return &st.speed }),
+	intParam("saturate", 0, pheromoneMax*4, func(st *GameState) *int { return &st.saturate }),
+	intParam("forager", 0, 1000, func(st *GameState) *int { return &st.castes[forager].ratio }),
+	intParam("scout", 0, 1000, func(st *GameState) *int { return &st.castes[scout].ratio }),
+	intParam("carrier", 0, 1000, func(st *GameState) *int { return &st.castes[carrier].ratio }),
+}
+
+func findParam(name string) (*param, error) {
+	for i := range params {
+		if params[i].name == name {
+			return &params[i], nil
+		}
+	}
+	names := make([]string, len(params))
+	for i := range params {
+		names[i] = params[i].name
+	}
+	return nil, fmt.Errorf("no parameter %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// setParam sets the named parameter, checking it's within the parameter's range.
+func (st *GameState) setParam(name string, v int) error {
+	p, err := findParam(name)
+	if err != nil {
+		return err
+	}
+	if v < p.min || v > p.max {
+		return fmt.Errorf("%s = %d is out of range [%d, %d]", name, v, p.min, p.max)
+	}
+	p.set(st, v)
+	return nil
+}
+
+// LoadConfig reads "name = value" lines into st. Blank lines and lines
+// starting with # are ignored.
+func (st *GameState) LoadConfig(r io.Reader) error {
+	s := bufio.NewScanner(r)
+	line := 0
+	for s.Scan() {
+		line++
+		l := strings.TrimSpace(s.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		name, val, ok := strings.Cut(l, "=")
+		if !ok {
+			return fmt.Errorf("line %d: expected name = value", line)
+		}
+		v, err := strconv.Atoi(strings.TrimSpace(val))
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		err = st.setParam(strings.TrimSpace(name), v)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return s.Err()
+}
+
+func (st *GameState) LoadConfigFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return st.LoadConfig(f)
+}
+
+// WriteConfig writes every parameter in the format LoadConfig reads.
+func (st *GameState) WriteConfig(w io.Writer) error {
+	for i := range params {
+		_, err := fmt.Fprintf(w, "%s = %d\n", params[i].name, params[i].get(st))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
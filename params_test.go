@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConfigRoundTrip(t *testing.T) {
+	st := NewGameState(100, 100)
+	st.sight = 42
+	st.followWalls = true
+	st.castes[scout].ratio = 7
+
+	var b bytes.Buffer
+	if err := st.WriteConfig(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	st2 := NewGameState(100, 100)
+	if err := st2.LoadConfig(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range params {
+		if a, b := p.get(&st), p.get(&st2); a != b {
+			t.Errorf("%s: wrote %d, read back %d", p.name, a, b)
+		}
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	for _, bad := range []string{"sight", "sight = x", "nosuchparam = 1", "sight = 0"} {
+		st := NewGameState(100, 100)
+		if err := st.LoadConfig(strings.NewReader("# comment\n\n" + bad)); err == nil {
+			t.Errorf("Expected an error loading %q", bad)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+// Pheromone only decays in tiles that may have some on them, so the cost of
+// decay scales with how much of the field ants have marked rather than with
+// its area. A tile is marked when pheromone is laid in it, and unmarked once
+// all of its pheromone has faded.
+const pherTile = 32
+
+// pherTiles tracks which tiles of the field may have pheromone on them.
+type pherTiles struct {
+	active []bool
+	w, h   int // Size of the field in tiles
+}
+
+func newPherTiles(width, height int) pherTiles {
+	w := (width + pherTile - 1) / pherTile
+	h := (height + pherTile - 1) / pherTile
+	return pherTiles{active: make([]bool, w*h), w: w, h: h}
+}
+
+// mark notes that the cell at (x, y) may have pheromone on it.
+func (t *pherTiles) mark(x, y int) {
+	t.active[x/pherTile+(y/pherTile)*t.w] = true
+}
+
+// markAll marks every tile, for when the whole field has been replaced.
+func (t *pherTiles) markAll() {
+	for i := range t.active {
+		t.active[i] = true
+	}
+}
+
+// bandRows returns how many rows each of n workers should take so that every
+// tile belongs to exactly one worker. Workers can then mark and unmark tiles
+// without stepping on each other.
+func bandRows(height, n int) int {
+	rows := height/n + 1
+	return (rows + pherTile - 1) / pherTile * pherTile
+}
+
+// decayTile fades the pheromone in tile (tx, ty), and unmarks it if there's
+// none left.
+func (as *AntScene) decayTile(tx, ty int) {
+	f := as.field
+	x0, y0 := tx*pherTile, ty*pherTile
+	x1, y1 := x0+pherTile, y0+pherTile
+	if x1 > f.width {
+		x1 = f.width
+	}
+	if y1 > f.height {
+		y1 = f.height
+	}
+	left := false
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			update := false
+			spot := f.Get(x, y)
+			if spot.FoodPher > 0 {
+				spot.FoodPher -= (spot.FoodPher / as.st.fadedivisor) + 1
+				update = true
+			}
+			if spot.HomePher > 0 {
+				spot.HomePher -= (spot.HomePher / as.st.fadedivisor) + 1
+				update = true
+			}
+			if spot.FoodPher > 0 || spot.HomePher > 0 {
+				left = true
+			}
+
+			if update && as.st.renderPher {
+				f.Update(x, y)
+			}
+		}
+	}
+	if !left {
+		as.pher.active[tx+ty*as.pher.w] = false
+	}
+}
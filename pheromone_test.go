@@ -0,0 +1,60 @@
+package main
+
+import "testing"
+
+func TestPherTiles(t *testing.T) {
+	as := testScene(t, 100, 70)
+	as.st.fadedivisor = 10
+	if as.pher.w != 4 || as.pher.h != 3 {
+		t.Fatalf("Expected a 4x3 grid of tiles, but got %dx%d", as.pher.w, as.pher.h)
+	}
+
+	as.field.Get(70, 40).FoodPher = 100
+	as.pher.mark(70, 40)
+	as.UpdatePherPartial(0, 70)
+	if p := as.field.Get(70, 40).FoodPher; p != 89 {
+		t.Errorf("Expected the pheromone to fade to 89, but it's %d", p)
+	}
+	for i := 0; i < 100; i++ {
+		as.UpdatePherPartial(0, 70)
+	}
+	if p := as.field.Get(70, 40).FoodPher; p > 0 {
+		t.Errorf("Expected the pheromone to have faded away, but it's %d", p)
+	}
+	for i, a := range as.pher.active {
+		if a {
+			t.Errorf("Tile %d is still marked after its pheromone faded", i)
+		}
+	}
+}
+
+func TestBandRows(t *testing.T) {
+	for _, c := range []struct{ height, n int }{{720, 1}, {720, 4}, {721, 3}, {70, 8}} {
+		rows := bandRows(c.height, c.n)
+		if rows%pherTile != 0 || rows*c.n < c.height {
+			t.Errorf("bandRows(%d, %d) = %d, which doesn't split the field into whole tiles", c.height, c.n, rows)
+		}
+	}
+}
+
+// BenchmarkUpdatePherSparse decays a large field with one trail on it.
+func BenchmarkUpdatePherSparse(b *testing.B) {
+	const w, h = 8000, 8000
+	st := NewGameState(w, h)
+	st.renderPher = false
+	as := &AntScene{st: &st}
+	f, err := NewField[gridspot](w, h, func(*gridspot) uint32 { return 0 })
+	if err != nil {
+		b.Fatal(err)
+	}
+	as.field = f
+	as.pher = newPherTiles(w, h)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for x := 1000; x < 2000; x++ {
+			as.field.Get(x, 4000).FoodPher = pheromoneMax
+			as.pher.mark(x, 4000)
+		}
+		as.UpdatePherPartial(0, h)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// With no ant being inspected, the probes of every senseSample'th ant are
+// drawn, without labels.
+const senseSample = 50
+
+var (
+	probeFoodColor   = color.RGBA{G: 0xff, A: 0xff}
+	probeHomeColor   = color.RGBA{R: 0xff, A: 0xff}
+	probeChosenColor = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+)
+
+func turnName(turn int) string {
+	switch {
+	case turn > 0:
+		return fmt.Sprintf("right %d", turn)
+	case turn < 0:
+		return fmt.Sprintf("left %d", -turn)
+	}
+	return "straight"
+}
+
+// drawSensing draws the probes of the inspected ant, or of a sample of ants
+// if none is being inspected.
+func (as *AntScene) drawSensing(screen *ebiten.Image) {
+	if as.inspect != nil {
+		if a, ok := as.ants.find(as.inspect.id); ok {
+			as.drawProbes(screen, a, true)
+		}
+		return
+	}
+	for a := 0; a < as.ants.len(); a += senseSample {
+		as.drawProbes(screen, a, false)
+	}
+}
+
+// drawProbes draws the five lines the ant senses along, as far as it can see
+// along each. Probes are green while the ant is looking for food and red while
+// it's heading home, and the one it would steer towards is white. This ignores
+// the random turns the ant takes.
+func (as *AntScene) drawProbes(screen *ebiten.Image, a int, labels bool) {
+	s := &as.ants
+	cg := as.st.casteGenomes()
+	gn := s.genes(as, a, &cg)
+	pr := s.sense(as, a, gn.sight)
+	turn := s.steer(as, a, &gn, pr, false)
+	chosen := as.right(s.dir[a], turn)
+	spots := [5]gridspot{pr.lleft, pr.left, pr.straight, pr.right, pr.rright}
+
+	for i, d := range s.probeDirs(as, a) {
+		var c color.Color = probeFoodColor
+		if s.food[a] > 0 {
+			c = probeHomeColor
+		}
+		if d == chosen {
+			c = probeChosenColor
+		}
+		// Step along the probe rather than drawing a line to its end, as it
+		// may wrap around the edges.
+		end := s.pos[a]
+		x, y := as.worldPixel(end)
+		screen.Set(x, y, c)
+		for n := 1; n < gn.sight; n++ {
+			next, ok := as.cell(as.step(end, d))
+			if !ok || as.field.Get(next.x, next.y).Wall {
+				break
+			}
+			end = next
+			x, y = as.worldPixel(end)
+			screen.Set(x, y, c)
+		}
+		if labels {
+			text.Draw(screen, fmt.Sprintf("F:%d H:%d", spots[i].FoodPher, spots[i].HomePher), as.panelFont, x+4, y, c)
+		}
+	}
+	if labels {
+		x, y := as.worldPixel(s.pos[a])
+		text.Draw(screen, "Steer: "+turnName(turn), as.panelFont, x+4, y+panelFontSpace, probeChosenColor)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import "math"
+
+type senseModel int
+
+const (
+	senseRays senseModel = iota
+	senseOctants
+	senseCone
+	endSense
+)
+
+func (m senseModel) String() string {
+	switch m {
+	case senseRays:
+		return "Rays"
+	case senseOctants:
+		return "Octants"
+	case senseCone:
+		return "Cone"
+	}
+	return "UNKNOWN"
+}
+
+// coneSlope is tan(22.5°). Each cone covers its direction plus half the gap to
+// the neighbouring directions on either side.
+const coneSlope = 0.41421356
+
+// addSample adds what an ant senses from cell g into pt. If saturate is
+// positive, no more than saturate of each pheromone is taken from the cell.
+// Food and the hive always stand out.
+func (pt *gridspot) addSample(g *gridspot, saturate int) {
+	fp, hp := g.FoodPher, g.HomePher
+	if saturate > 0 {
+		if fp > saturate {
+			fp = saturate
+		}
+		if hp > saturate {
+			hp = saturate
+		}
+	}
+	pt.FoodPher += fp + g.Food*pheromoneMax*2
+	pt.HomePher += hp
+	if g.Home {
+		pt.HomePher += pheromoneMax * 2
+	}
+}
+
+// probe senses in direction d using the configured sensing model. The
+// octant and cone models are square, so the hex grid always senses in rays.
+func (an *AntScene) probe(pos point, d direction, sight int) gridspot {
+	if an.hexGrid() {
+		return an.Line(pos, d, sight)
+	}
+	switch an.st.sensing {
+	case senseOctants:
+		return an.SumOctant(pos, d, sight)
+	case senseCone:
+		return an.Cone(pos, d, sight)
+	}
+	return an.Line(pos, d, sight)
+}
+
+// Cone sums the cells in a 45° cone facing d, out to size cells away. Walls
+// don't block the cone, but like Line, it's marked as a wall if one is
+// straight ahead.
+func (an *AntScene) Cone(pos point, d direction, size int) gridspot {
+	var pt gridspot
+	angle := dirAngle(d)
+	fx, fy := math.Sin(angle), -math.Cos(angle)
+	// Perpendicular to the direction, pointing right.
+	px, py := -fy, fx
+	for r := 1; r < size; r++ {
+		w := int(float64(r) * coneSlope)
+		for l := -w; l <= w; l++ {
+			p, ok := an.cell(point{
+				pos.x + int(math.Round(float64(r)*fx+float64(l)*px)),
+				pos.y + int(math.Round(float64(r)*fy+float64(l)*py)),
+			})
+			if !ok {
+				if l == 0 {
+					pt.Wall = true
+				}
+				continue
+			}
+			g := an.field.Get(p.x, p.y)
+			if g.Wall {
+				if l == 0 {
+					pt.Wall = true
+				}
+				continue
+			}
+			pt.addSample(g, an.st.saturate)
+		}
+	}
+	return pt
+}
@@ -0,0 +1,60 @@
+package main
+
+import "testing"
+
+// testScene returns an empty w by h scene that can Step without rendering.
+func testScene(t *testing.T, w, h int) *AntScene {
+	t.Helper()
+	st := NewGameState(w, h)
+	st.parallel = false
+	as := &AntScene{st: &st, quiet: true, pher: newPherTiles(w, h)}
+	f, err := NewField[gridspot](w, h, func(*gridspot) uint32 { return 0 })
+	if err != nil {
+		t.Fatal(err)
+	}
+	as.field = f
+	return as
+}
+
+func TestSensingModels(t *testing.T) {
+	as := testScene(t, 40, 40)
+	as.field.Get(20, 15).FoodPher = 1000
+	p := point{20, 20}
+
+	for m := senseRays; m < endSense; m++ {
+		as.st.sensing = m
+		if pt := as.probe(p, N, 10); pt.FoodPher != 1000 {
+			t.Errorf("%s: expected to sense 1000 food pheromone to the north, but sensed %d", m, pt.FoodPher)
+		}
+		if pt := as.probe(p, S, 10); pt.FoodPher != 0 {
+			t.Errorf("%s: expected to sense nothing to the south, but sensed %d", m, pt.FoodPher)
+		}
+
+		as.st.saturate = 300
+		if pt := as.probe(p, N, 10); pt.FoodPher != 300 {
+			t.Errorf("%s: expected saturation to cap the pheromone at 300, but sensed %d", m, pt.FoodPher)
+		}
+		as.st.saturate = 0
+	}
+}
+
+func TestSensingWalls(t *testing.T) {
+	as := testScene(t, 40, 40)
+	p := point{20, 0}
+	// Off to the side of the line north from (20, 20), but in its octant
+	// and cone.
+	as.field.Get(23, 12).Wall = true
+
+	for m := senseRays; m < endSense; m++ {
+		as.st.sensing = m
+		if pt := as.probe(p, N, 10); !pt.Wall {
+			t.Errorf("%s: expected the edge of the field to the north to count as a wall", m)
+		}
+		if pt := as.probe(p, S, 10); pt.Wall {
+			t.Errorf("%s: expected no wall to the south", m)
+		}
+		if pt := as.probe(point{20, 20}, N, 10); pt.Wall {
+			t.Errorf("%s: expected a wall off to the side not to block the way north", m)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// fieldShaderSrc colours the field on the GPU from its raw channels, the same
+// way renderGridspot does on the CPU.
+//
+// Image 0 holds the home pheromone in r (low byte) and g (high byte) and the
+// low byte of the food pheromone in b. Image 1 holds the high byte of the food
+// pheromone in r and the cell's flags in g: 1 for a wall, 2 for food and 4
+// for the hive.
+const fieldShaderSrc = `//kage:unit pixels
+
+package main
+
+var ShowPher float
+var ShowFood float
+var ShowHome float
+var Threshold float
+var Hex float
+var HexScale float
+var HexRadius float
+var Scale float
+var Palette float
+
+func channel(lo, hi float) float {
+	return floor(lo*255+0.5) + floor(hi*255+0.5)*256
+}
+
+// level maps an amount of pheromone to 0..1, like pherScale.level.
+func level(v float) float {
+	v = clamp(v, 0, 8191)
+	if Scale == 1 {
+		return floor(log(1+v)/log(1+8191)*255+0.5) / 255
+	}
+	return floor(v*255/8191) / 255
+}
+
+func viridis(t float) vec3 {
+	c0 := vec3(0.2777273272234177, 0.005407344544966578, 0.3340998053353061)
+	c1 := vec3(0.1050930431085774, 1.404613529898575, 1.384590162594685)
+	c2 := vec3(-0.3308618287255563, 0.214847559468213, 0.09509516302823659)
+	c3 := vec3(-4.634230498983486, -5.799100973351585, -19.33244095627987)
+	c4 := vec3(6.228269936347081, 14.17993336680509, 56.69055260068105)
+	c5 := vec3(4.776384997670288, -13.74514537774601, -65.35303263337234)
+	c6 := vec3(-5.435455855934631, 4.645852612178535, 26.3124352495832)
+	return c0 + t*(c1+t*(c2+t*(c3+t*(c4+t*(c5+t*c6)))))
+}
+
+func magma(t float) vec3 {
+	c0 := vec3(-0.002136485053939582, -0.000749655052795221, -0.005386127855323933)
+	c1 := vec3(0.2516605407371642, 0.6775232436837668, 2.494026599312351)
+	c2 := vec3(8.353717279216625, -3.577719514958484, 0.3144679030132573)
+	c3 := vec3(-27.66873308576866, 14.26473078096533, -13.64921318813922)
+	c4 := vec3(52.17613981234068, -27.94360607168351, 12.94416944238394)
+	c5 := vec3(-50.76852536473588, 29.04658282127291, 4.23415299384598)
+	c6 := vec3(18.65570506591883, -11.48977351997711, -5.601961508734096)
+	return c0 + t*(c1+t*(c2+t*(c3+t*(c4+t*(c5+t*c6)))))
+}
+
+// pherColor colours pheromone levels, like pherPalette.color.
+func pherColor(food, home float) vec3 {
+	if Palette == 0 {
+		return vec3(home, food, 0)
+	}
+	if Palette == 1 {
+		return min(food*vec3(0x00, 0x72, 0xB2)/255+home*vec3(0xE6, 0x9F, 0x00)/255, 1)
+	}
+	if Palette == 2 {
+		return clamp(viridis(max(food, home)), 0, 1)
+	}
+	return clamp(magma(max(food, home)), 0, 1)
+}
+
+func cellColor(pos vec2) vec4 {
+	p := imageSrc0At(pos)
+	c := imageSrc1At(pos)
+	flags := floor(c.g*255 + 0.5)
+	if mod(flags, 2) >= 1 {
+		return vec4(0.2, 0.2, 0.2, 1)
+	}
+	if mod(floor(flags/2), 2) >= 1 {
+		return vec4(0.2, 1, 0.2, 1)
+	}
+	if floor(flags/4) >= 1 {
+		return vec4(1, 0.2, 0.2, 1)
+	}
+	if ShowPher == 0 {
+		return vec4(0)
+	}
+	home := channel(p.r, p.g)
+	food := channel(p.b, c.r)
+	if home < Threshold {
+		home = 0
+	}
+	if food < Threshold {
+		food = 0
+	}
+	return vec4(pherColor(level(food)*ShowFood, level(home)*ShowHome), 1)
+}
+
+// hexCell returns the cell whose hexagon covers pos, like hexCellAt.
+func hexCell(pos vec2) vec2 {
+	pos -= vec2(HexScale/2, HexRadius)
+	q := (pos.x*sqrt(3.0)/3 - pos.y/3) / HexRadius
+	r := pos.y * 2 / 3 / HexRadius
+	c := vec3(q, r, -q-r)
+	rc := floor(c + 0.5)
+	d := abs(rc - c)
+	if d.x > d.y && d.x > d.z {
+		rc.x = -rc.y - rc.z
+	} else if d.y > d.z {
+		rc.y = -rc.x - rc.z
+	}
+	return vec2(rc.x+(rc.y-mod(rc.y, 2))/2, rc.y)
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	if Hex > 0 {
+		// The world image is larger than the field, so the cell is worked
+		// out from where the pixel is drawn.
+		cell := hexCell(dstPos.xy - imageDstOrigin())
+		size := imageSrc0Size()
+		if cell.x < 0 || cell.y < 0 || cell.x >= size.x || cell.y >= size.y {
+			return vec4(0)
+		}
+		return cellColor(cell + 0.5 + imageSrc0Origin())
+	}
+	return cellColor(srcPos)
+}
+`
+
+const (
+	cellWall = 1 << iota
+	cellFood
+	cellHome
+)
+
+// fieldShader renders the field with fieldShaderSrc. Only the raw channels
+// of changed cells are packed on the CPU; all the colouring is done by the
+// shader.
+type fieldShader struct {
+	shader           *ebiten.Shader
+	pher, cells      *ebiten.Image
+	pherbuf, cellbuf []byte
+}
+
+func newFieldShader(w, h int) (*fieldShader, error) {
+	s, err := ebiten.NewShader([]byte(fieldShaderSrc))
+	if err != nil {
+		return nil, err
+	}
+	return &fieldShader{
+		shader:  s,
+		pher:    ebiten.NewImage(w, h),
+		cells:   ebiten.NewImage(w, h),
+		pherbuf: make([]byte, w*h*4),
+		cellbuf: make([]byte, w*h*4),
+	}, nil
+}
+
+func clampPher(v int) int {
+	if v < 0 {
+		return 0
+	}
+	if v > pheromoneMax {
+		return pheromoneMax
+	}
+	return v
+}
+
+// pack writes the channels of the cells in changed tiles into the shader's
+// source buffers, and reports whether there were any.
+func (fs *fieldShader) pack(f *Field[gridspot]) bool {
+	t := &f.changed
+	packed := false
+	for ti, ok := range t.active {
+		if !ok {
+			continue
+		}
+		t.active[ti] = false
+		packed = true
+		x0, y0 := ti%t.w*pherTile, ti/t.w*pherTile
+		x1, y1 := x0+pherTile, y0+pherTile
+		if x1 > f.width {
+			x1 = f.width
+		}
+		if y1 > f.height {
+			y1 = f.height
+		}
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				fs.packCell(f, x+y*f.width)
+			}
+		}
+	}
+	return packed
+}
+
+// packCell writes the channels of cell i into the source buffers.
+func (fs *fieldShader) packCell(f *Field[gridspot], i int) {
+	g := &f.vals[i]
+	home, food := clampPher(g.HomePher), clampPher(g.FoodPher)
+	var flags byte
+	if g.Wall {
+		flags |= cellWall
+	}
+	if g.Food > 0 {
+		flags |= cellFood
+	}
+	if g.Home {
+		flags |= cellHome
+	}
+	p, c := fs.pherbuf[i*4:i*4+4], fs.cellbuf[i*4:i*4+4]
+	p[0], p[1], p[2], p[3] = byte(home), byte(home>>8), byte(food), 0xff
+	c[0], c[1], c[2], c[3] = byte(food>>8), flags, 0, 0xff
+}
+
+func boolUniform(b bool) float32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// RenderShader draws the field onto r with the field shader.
+func (as *AntScene) RenderShader(r *ebiten.Image) error {
+	if as.shader == nil {
+		fs, err := newFieldShader(as.field.width, as.field.height)
+		if err != nil {
+			return err
+		}
+		as.shader = fs
+		as.field.changed.markAll()
+	}
+	fs := as.shader
+	// Only cells the field has Updated since the last frame are packed
+	// again, and the images are left alone if there weren't any.
+	if fs.pack(as.field) {
+		fs.pher.WritePixels(fs.pherbuf)
+		fs.cells.WritePixels(fs.cellbuf)
+	}
+
+	var op ebiten.DrawRectShaderOptions
+	op.Images[0] = fs.pher
+	op.Images[1] = fs.cells
+	op.Uniforms = map[string]any{
+		"ShowPher":  boolUniform(as.st.renderPher),
+		"ShowFood":  boolUniform(as.st.renderGreen),
+		"ShowHome":  boolUniform(as.st.renderRed),
+		"Threshold": float32(as.st.threshold),
+		"Hex":       boolUniform(as.hexGrid()),
+		"Scale":     float32(as.st.pherScale),
+		"Palette":   float32(as.st.palette),
+	}
+	if !as.hexGrid() {
+		r.DrawRectShader(as.field.width, as.field.height, fs.shader, &op)
+		return nil
+	}
+
+	// DrawRectShader can't draw a rectangle larger than its images, so the
+	// hex world is covered with two triangles instead.
+	op.Uniforms["HexScale"] = float32(hexScale)
+	op.Uniforms["HexRadius"] = float32(hexRadius)
+	w, h := as.worldSize()
+	fw, fh := float32(as.field.width), float32(as.field.height)
+	var vs [4]ebiten.Vertex
+	for i, c := range [4][2]float32{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+		vs[i] = ebiten.Vertex{
+			DstX: c[0] * float32(w), DstY: c[1] * float32(h),
+			SrcX: c[0] * fw, SrcY: c[1] * fh,
+			ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1,
+		}
+	}
+	r.DrawTrianglesShader(vs[:], []uint16{0, 1, 2, 1, 3, 2}, fs.shader, &ebiten.DrawTrianglesShaderOptions{
+		Uniforms: op.Uniforms,
+		Images:   op.Images,
+	})
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestFieldShaderPack(t *testing.T) {
+	as := testScene(t, 4, 1)
+	f := as.field
+	*f.Get(0, 0) = gridspot{Wall: true}
+	*f.Get(1, 0) = gridspot{Food: 3, Home: true}
+	*f.Get(2, 0) = gridspot{FoodPher: 5000, HomePher: 300}
+	*f.Get(3, 0) = gridspot{FoodPher: pheromoneMax + 100, HomePher: -7}
+
+	f.UpdateAll()
+	fs := &fieldShader{pherbuf: make([]byte, 16), cellbuf: make([]byte, 16)}
+	if !fs.pack(f) {
+		t.Fatalf("Expected the updated field to be packed")
+	}
+	for x, want := range []struct {
+		home, food int
+		flags      byte
+	}{
+		{0, 0, cellWall},
+		{0, 0, cellFood | cellHome},
+		{300, 5000, 0},
+		{0, pheromoneMax, 0},
+	} {
+		p, c := fs.pherbuf[x*4:x*4+4], fs.cellbuf[x*4:x*4+4]
+		home := int(p[0]) | int(p[1])<<8
+		food := int(p[2]) | int(c[0])<<8
+		if home != want.home || food != want.food || c[1] != want.flags {
+			t.Errorf("Cell %d: unpacked home %d, food %d, flags %d; want %d, %d, %d",
+				x, home, food, c[1], want.home, want.food, want.flags)
+		}
+		if p[3] != 0xff || c[3] != 0xff {
+			t.Errorf("Cell %d: source pixels should be opaque", x)
+		}
+	}
+
+	if fs.pack(f) {
+		t.Errorf("Expected nothing to be packed when no cells were updated")
+	}
+	f.Get(2, 0).HomePher = 1
+	f.Update(2, 0)
+	if !fs.pack(f) || fs.pherbuf[8] != 1 {
+		t.Errorf("Expected an updated cell to be packed again, got home low byte %d", fs.pherbuf[8])
+	}
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type runResult struct {
+	delivered int64   // Food delivered to the hive
+	ants      int     // Population at the end of the run
+	meanAnts  float64 // Mean population over the run
+	peakAnts  int
+	tripLen   float64 // Mean distance travelled on the last tripWindow trips
+	lifeTrips float64 // Mean trips completed by the last tripWindow ants to die
+	pathEff   float64 // Mean path efficiency of the last tripWindow ways home
+}
+
+// runOnce runs one headless simulation for the given number of ticks. It runs
+// serially with its own random source, so the same seed gives the same result
+// and many runs can go at once.
+func runOnce(st GameState, homelife int64, grid string, ticks int, seed int64) (runResult, error) {
+	st.parallel = false
+	st.renderPher = false
+	as, err := newHeadlessScene(&st, homelife, grid)
+	if err != nil {
+		return runResult{}, err
+	}
+	defer as.Close()
+	as.rng = rand.New(rand.NewSource(seed))
+
+	var (
+		r   runResult
+		sum int64
+	)
+	for t := 0; t < ticks; t++ {
+		as.Step()
+		n := as.ants.len()
+		sum += int64(n)
+		if n > r.peakAnts {
+			r.peakAnts = n
+		}
+	}
+	r.delivered = as.delivered
+	r.ants = as.ants.len()
+	r.tripLen = as.trips.lengths.mean()
+	r.lifeTrips = as.trips.lives.mean()
+	r.pathEff = as.trips.efficiency.mean()
+	if ticks > 0 {
+		r.meanAnts = float64(sum) / float64(ticks)
+	}
+	return r, nil
+}
+
+type sweepAxis struct {
+	p      *param
+	values []int
+}
+
+// parseSweep parses a parameter grid like "fadedivisor=300,700;sight=5:20:5".
+// Values are either a comma separated list or a start:end:step range, with end
+// included.
+func parseSweep(spec string) ([]sweepAxis, error) {
+	var axes []sweepAxis
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, vals, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("bad sweep axis %q, expected name=values", part)
+		}
+		p, err := findParam(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		ax := sweepAxis{p: p}
+		if r := strings.Split(vals, ":"); len(r) == 3 {
+			var se [3]int
+			for i := range r {
+				se[i], err = strconv.Atoi(strings.TrimSpace(r[i]))
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", p.name, err)
+				}
+			}
+			if se[2] <= 0 {
+				return nil, fmt.Errorf("%s: range step must be positive", p.name)
+			}
+			for v := se[0]; v <= se[1]; v += se[2] {
+				ax.values = append(ax.values, v)
+			}
+		} else {
+			for _, v := range strings.Split(vals, ",") {
+				n, err := strconv.Atoi(strings.TrimSpace(v))
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", p.name, err)
+				}
+				ax.values = append(ax.values, n)
+			}
+		}
+		for _, v := range ax.values {
+			if v < p.min || v > p.max {
+				return nil, fmt.Errorf("%s = %d is out of range [%d, %d]", p.name, v, p.min, p.max)
+			}
+		}
+		if len(ax.values) == 0 {
+			return nil, fmt.Errorf("%s: no values", p.name)
+		}
+		axes = append(axes, ax)
+	}
+	return axes, nil
+}
+
+// sweepCombos returns every combination of the axis values, in order, with
+// the last axis varying fastest.
+func sweepCombos(axes []sweepAxis) [][]int {
+	combos := [][]int{{}}
+	for _, ax := range axes {
+		var next [][]int
+		for _, c := range combos {
+			for _, v := range ax.values {
+				n := append(append([]int{}, c...), v)
+				next = append(next, n)
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+// runOpts are the settings shared by every run of a sweep or optimisation.
+type runOpts struct {
+	ticks    int
+	seeds    int   // Number of runs per configuration
+	seed     int64 // Seed of the first run of each configuration. The others use seed+1, seed+2...
+	grid     string
+	homelife int64
+	jobs     int // Number of runs going at once
+}
+
+// runAll runs each configuration o.seeds times, o.jobs runs at a time, and
+// returns the results indexed by configuration then seed.
+func runAll(configs []GameState, o runOpts, label string) ([][]runResult, error) {
+	if o.seeds < 1 {
+		o.seeds = 1
+	}
+	if o.jobs < 1 {
+		o.jobs = 1
+	}
+
+	type job struct {
+		config, seed int
+	}
+	results := make([][]runResult, len(configs))
+	for i := range results {
+		results[i] = make([]runResult, o.seeds)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		lock     sync.Mutex
+		firstErr error
+		done     int
+	)
+	jobs := make(chan job)
+	for i := 0; i < o.jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				r, err := runOnce(configs[j.config], o.homelife, o.grid, o.ticks, o.seed+int64(j.seed))
+				lock.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				results[j.config][j.seed] = r
+				done++
+				fmt.Fprintf(os.Stderr, "%s: %d/%d runs done\n", label, done, len(configs)*o.seeds)
+				lock.Unlock()
+			}
+		}()
+	}
+	for c := range configs {
+		for s := 0; s < o.seeds; s++ {
+			jobs <- job{c, s}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return results, firstErr
+}
+
+type sweepOpts struct {
+	runOpts
+	axes []sweepAxis
+}
+
+// runSweep runs every combination of the sweep axes, seeds times each, and
+// writes a CSV summary table to w.
+func runSweep(base GameState, o sweepOpts, w io.Writer) error {
+	combos := sweepCombos(o.axes)
+	configs := make([]GameState, len(combos))
+	for c := range combos {
+		configs[c] = base
+		for a, ax := range o.axes {
+			ax.p.set(&configs[c], combos[c][a])
+		}
+	}
+	results, err := runAll(configs, o.runOpts, "sweep")
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	var header []string
+	for _, ax := range o.axes {
+		header = append(header, ax.p.name)
+	}
+	header = append(header, "seeds", "delivered_mean", "delivered_stddev", "delivered_per_tick", "ants_final", "ants_mean", "ants_peak", "trip_length_mean", "lifetime_trips_mean", "path_efficiency_mean")
+	cw.Write(header)
+	for c := range combos {
+		var row []string
+		for _, v := range combos[c] {
+			row = append(row, strconv.Itoa(v))
+		}
+		s := summarise(results[c])
+		perTick := 0.0
+		if o.ticks > 0 {
+			perTick = s.delivered / float64(o.ticks)
+		}
+		row = append(row,
+			strconv.Itoa(len(results[c])),
+			strconv.FormatFloat(s.delivered, 'f', 1, 64),
+			strconv.FormatFloat(s.deliveredStddev, 'f', 1, 64),
+			strconv.FormatFloat(perTick, 'f', 3, 64),
+			strconv.FormatFloat(s.ants, 'f', 1, 64),
+			strconv.FormatFloat(s.meanAnts, 'f', 1, 64),
+			strconv.FormatFloat(s.peakAnts, 'f', 1, 64),
+			strconv.FormatFloat(s.tripLen, 'f', 1, 64),
+			strconv.FormatFloat(s.lifeTrips, 'f', 2, 64),
+			strconv.FormatFloat(s.pathEff, 'f', 3, 64))
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type runSummary struct {
+	delivered, deliveredStddev  float64
+	ants, meanAnts, peakAnts    float64
+	tripLen, lifeTrips, pathEff float64
+}
+
+// summarise averages a set of runs of the same configuration.
+func summarise(rs []runResult) runSummary {
+	var s runSummary
+	if len(rs) == 0 {
+		return s
+	}
+	n := float64(len(rs))
+	for _, r := range rs {
+		s.delivered += float64(r.delivered)
+		s.ants += float64(r.ants)
+		s.meanAnts += r.meanAnts
+		s.peakAnts += float64(r.peakAnts)
+		s.tripLen += r.tripLen
+		s.lifeTrips += r.lifeTrips
+		s.pathEff += r.pathEff
+	}
+	s.delivered /= n
+	s.ants /= n
+	s.meanAnts /= n
+	s.peakAnts /= n
+	s.tripLen /= n
+	s.lifeTrips /= n
+	s.pathEff /= n
+	for _, r := range rs {
+		d := float64(r.delivered) - s.delivered
+		s.deliveredStddev += d * d
+	}
+	s.deliveredStddev = math.Sqrt(s.deliveredStddev / n)
+	return s
+}
@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+func TestParseSweep(t *testing.T) {
+	axes, err := parseSweep("fadedivisor=300,700; sight=5:20:5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(axes) != 2 || axes[0].p.name != "fadedivisor" || axes[1].p.name != "sight" {
+		t.Fatalf("Unexpected axes: %+v", axes)
+	}
+	if v := axes[1].values; len(v) != 4 || v[0] != 5 || v[3] != 20 {
+		t.Errorf("sight=5:20:5 should give 5, 10, 15, 20, but gave %v", v)
+	}
+
+	if combos := sweepCombos(axes); len(combos) != 8 || combos[1][0] != 300 || combos[1][1] != 10 {
+		t.Errorf("Unexpected combinations: %v", combos)
+	}
+
+	for _, bad := range []string{"nosuchparam=1", "sight", "sight=a", "sight=0", "sight=5:1:0"} {
+		if _, err := parseSweep(bad); err == nil {
+			t.Errorf("Expected an error parsing %q", bad)
+		}
+	}
+}
+
+func testState() GameState {
+	st := NewGameState(200, 150)
+	st.maxants = 300
+	st.antlife = 500
+	return st
+}
+
+func TestRunOnceDeterministic(t *testing.T) {
+	r1, err := runOnce(testState(), 1000000, "", 300, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2, err := runOnce(testState(), 1000000, "", 300, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r1 != r2 {
+		t.Errorf("Runs with the same seed differ: %+v, %+v", r1, r2)
+	}
+	if r1.peakAnts == 0 {
+		t.Errorf("No ants spawned: %+v", r1)
+	}
+}
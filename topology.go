@@ -0,0 +1,55 @@
+package main
+
+// wrapi returns i modulo n, always in [0, n).
+func wrapi(i, n int) int {
+	i %= n
+	if i < 0 {
+		i += n
+	}
+	return i
+}
+
+// cell returns the field cell at p. If the world wraps, points off one edge
+// come back on the opposite one. Otherwise ok is false for points off the
+// field.
+func (as *AntScene) cell(p point) (point, bool) {
+	if p.Within(0, 0, as.field.width, as.field.height) {
+		return p, true
+	}
+	if !as.st.wrap {
+		return p, false
+	}
+	return point{wrapi(p.x, as.field.width), wrapi(p.y, as.field.height)}, true
+}
+
+// wrapCopies calls f with p, and if the world wraps and p is close enough to
+// an edge that an ant drawn there would be cut off, with p moved to just past
+// the opposite edge too.
+func (as *AntScene) wrapCopies(p point, f func(x, y int)) {
+	f(p.x, p.y)
+	if !as.st.wrap {
+		return
+	}
+	const m = antTexSize / 2
+	w, h := as.field.width, as.field.height
+	dx, dy := 0, 0
+	if p.x < m {
+		dx = w
+	} else if p.x >= w-m {
+		dx = -w
+	}
+	if p.y < m {
+		dy = h
+	} else if p.y >= h-m {
+		dy = -h
+	}
+	if dx != 0 {
+		f(p.x+dx, p.y)
+	}
+	if dy != 0 {
+		f(p.x, p.y+dy)
+	}
+	if dx != 0 && dy != 0 {
+		f(p.x+dx, p.y+dy)
+	}
+}
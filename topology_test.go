@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestWrap(t *testing.T) {
+	as := testScene(t, 40, 40)
+	as.field.Get(39, 5).FoodPher = 1000
+	p := point{0, 5}
+
+	if _, ok := as.GridAt(p, W); ok {
+		t.Errorf("Without wrapping, there should be nothing west of the left edge")
+	}
+	if pt := as.Line(p, W, 5); !pt.Wall || pt.FoodPher != 0 {
+		t.Errorf("Without wrapping, the left edge should look like a wall, but sensed %+v", pt)
+	}
+
+	as.st.wrap = true
+	for m := senseRays; m < endSense; m++ {
+		as.st.sensing = m
+		if pt := as.probe(p, W, 10); pt.Wall || pt.FoodPher != 1000 {
+			t.Errorf("%s: expected to sense 1000 food pheromone across the left edge, but sensed %+v", m, pt)
+		}
+	}
+
+	if g, ok := as.GridAt(p, W); !ok || g.FoodPher != 1000 {
+		t.Errorf("Expected the cell west of the left edge to be (39, 5)")
+	}
+
+	as.st.speed = 100
+	as.ants.add(Ant{pos: point{5, 0}, dir: N})
+	as.ants.glide(as, 0)
+	if a := as.ants.get(0); a.pos != (point{5, 39}) || a.fy < 39 || a.fy >= 40 {
+		t.Errorf("Expected the ant to glide over the top edge into (5, 39), but it's at (%f, %f) in %v", a.fx, a.fy, a.pos)
+	}
+}
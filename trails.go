@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// tagBatch is how many ants are tagged at once by the tag key.
+const tagBatch = 10
+
+type trailPoint struct {
+	tick     uint64
+	pos      point
+	carrying bool
+}
+
+// trail is everywhere a tagged ant has been. A point is only added when the
+// ant moves to another cell or picks up or drops food.
+type trail struct {
+	id     uint64
+	points []trailPoint
+	alive  bool
+	color  color.RGBA
+}
+
+// tracer records the trails of the tagged ants. Trails of ants that have
+// died, or been untagged, are kept until the tracer is cleared.
+type tracer struct {
+	trails []*trail
+	live   map[uint64]*trail
+}
+
+func (t *tracer) clear() {
+	t.trails = nil
+	t.live = nil
+}
+
+// tag starts tracing ant i.
+func (as *AntScene) tag(i int) {
+	if as.ants.tagged[i] {
+		return
+	}
+	as.ants.tagged[i] = true
+	t := &as.trails
+	if t.live == nil {
+		t.live = make(map[uint64]*trail)
+	}
+	// Trails are told apart by hue, spread around the colour wheel by the
+	// golden ratio so neighbouring tags differ the most.
+	c := hsv(float64(len(t.trails))*0.618034, 1)
+	tr := &trail{id: as.ants.id[i], alive: true, color: color.RGBA{R: uint8(c), G: uint8(c >> 8), B: uint8(c >> 16), A: 0xff}}
+	tr.points = append(tr.points, trailPoint{as.frame, as.ants.pos[i], as.ants.food[i] > 0})
+	t.trails = append(t.trails, tr)
+	t.live[tr.id] = tr
+}
+
+// untag stops tracing ant i, keeping its trail.
+func (as *AntScene) untag(i int) {
+	as.ants.tagged[i] = false
+	as.trails.end(as.ants.id[i])
+}
+
+// untagAll stops tracing every ant and forgets all the trails.
+func (as *AntScene) untagAll() {
+	for i := range as.ants.tagged {
+		as.ants.tagged[i] = false
+	}
+	as.trails.clear()
+}
+
+// tagRandom tags up to n ants that aren't tagged yet, picked at random.
+func (as *AntScene) tagRandom(n int) {
+	var untagged []int
+	for i, t := range as.ants.tagged {
+		if !t {
+			untagged = append(untagged, i)
+		}
+	}
+	for ; n > 0 && len(untagged) > 0; n-- {
+		j := as.intn(len(untagged))
+		as.tag(untagged[j])
+		untagged = swapRemove(untagged, j)
+	}
+}
+
+// toggleTag tags or untags the ant closest to (x, y).
+func (as *AntScene) toggleTag(x, y int) {
+	i, ok := as.nearestAnt(x, y)
+	if !ok {
+		return
+	}
+	if as.ants.tagged[i] {
+		as.untag(i)
+	} else {
+		as.tag(i)
+	}
+}
+
+// end marks the trail of ant id as finished.
+func (t *tracer) end(id uint64) {
+	if tr, ok := t.live[id]; ok {
+		tr.alive = false
+		delete(t.live, id)
+	}
+}
+
+// recordTrails adds the tagged ants' positions after a tick.
+func (as *AntScene) recordTrails() {
+	if len(as.trails.live) == 0 {
+		return
+	}
+	for i, tagged := range as.ants.tagged {
+		if !tagged {
+			continue
+		}
+		tr := as.trails.live[as.ants.id[i]]
+		p := trailPoint{as.frame, as.ants.pos[i], as.ants.food[i] > 0}
+		last := tr.points[len(tr.points)-1]
+		if p.pos != last.pos || p.carrying != last.carrying {
+			tr.points = append(tr.points, p)
+		}
+	}
+}
+
+// draw draws each trail as a polyline that fades out over fade ticks.
+func (t *tracer) draw(as *AntScene, world *ebiten.Image, fade int) {
+	if fade < 1 {
+		fade = 1
+	}
+	for _, tr := range t.trails {
+		for i := len(tr.points) - 1; i > 0; i-- {
+			p0, p1 := tr.points[i-1], tr.points[i]
+			age := as.frame - p1.tick
+			if age >= uint64(fade) {
+				break
+			}
+			if as.st.wrap && (absi(p1.pos.x-p0.pos.x) > as.field.width/2 || absi(p1.pos.y-p0.pos.y) > as.field.height/2) {
+				// The ant went over an edge.
+				continue
+			}
+			// Colours are premultiplied by alpha.
+			a := 1 - float64(age)/float64(fade)
+			c := color.RGBA{
+				R: uint8(float64(tr.color.R) * a),
+				G: uint8(float64(tr.color.G) * a),
+				B: uint8(float64(tr.color.B) * a),
+				A: uint8(255 * a),
+			}
+			x0, y0 := as.worldPos(p0.pos)
+			x1, y1 := as.worldPos(p1.pos)
+			vector.StrokeLine(world, float32(x0), float32(y0), float32(x1), float32(y1), 1, c, false)
+		}
+	}
+}
+
+// WriteTrails writes every point of every trail as CSV.
+func (t *tracer) WriteTrails(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"id", "tick", "x", "y", "carrying", "alive"})
+	if err != nil {
+		return err
+	}
+	for _, tr := range t.trails {
+		for _, p := range tr.points {
+			err = cw.Write([]string{
+				strconv.FormatUint(tr.id, 10),
+				strconv.FormatUint(p.tick, 10),
+				strconv.Itoa(p.pos.x),
+				strconv.Itoa(p.pos.y),
+				strconv.FormatBool(p.carrying),
+				strconv.FormatBool(tr.alive),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (as *AntScene) saveTrails() error {
+	path, err := captureName(as.st.captureDir, "-trails.csv")
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = as.trails.WriteTrails(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	fmt.Printf("Saved trails %s\n", path)
+	return f.Close()
+}
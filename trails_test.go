@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTrails(t *testing.T) {
+	as := testScene(t, 20, 20)
+	as.ants.add(Ant{pos: point{1, 1}, id: 7, life: 100})
+	as.ants.add(Ant{pos: point{5, 5}, id: 8, life: 1})
+	as.tag(0)
+	as.tag(1)
+
+	as.frame = 1
+	as.recordTrails()
+	as.ants.pos[0] = point{2, 1}
+	as.frame = 2
+	as.recordTrails()
+	as.ants.food[0] = 3
+	as.frame = 3
+	as.recordTrails()
+
+	tr := as.trails.trails[0]
+	if len(tr.points) != 3 {
+		t.Fatalf("Expected the start, the move and the pickup to be recorded, got %+v", tr.points)
+	}
+	if p := tr.points[1]; p.tick != 2 || p.pos != (point{2, 1}) || p.carrying {
+		t.Errorf("Expected a move to (2, 1) on tick 2, got %+v", p)
+	}
+
+	// Ant 8 dies.
+	as.Step()
+	as.Step()
+	if as.trails.trails[1].alive || len(as.trails.live) != 1 {
+		t.Errorf("Expected the dead ant's trail to have ended")
+	}
+
+	var buf bytes.Buffer
+	if err := as.trails.WriteTrails(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != "id,tick,x,y,carrying,alive" || lines[3] != "7,3,2,1,true,true" {
+		t.Errorf("Unexpected CSV:\n%s", buf.String())
+	}
+
+	as.untagAll()
+	if as.ants.tagged[0] || len(as.trails.trails) != 0 {
+		t.Errorf("Expected untagging to forget every trail")
+	}
+}
+
+func TestTagRandom(t *testing.T) {
+	as := testScene(t, 20, 20)
+	for i := 0; i < 5; i++ {
+		as.ants.add(Ant{id: uint64(i + 1)})
+	}
+	as.tagRandom(3)
+	as.tagRandom(3)
+	n := 0
+	for _, tg := range as.ants.tagged {
+		if tg {
+			n++
+		}
+	}
+	if n != 5 || len(as.trails.trails) != 5 {
+		t.Errorf("Expected every ant to be tagged once, got %d tagged and %d trails", n, len(as.trails.trails))
+	}
+}
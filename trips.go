@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// A trip is everything an ant does between one delivery of food to the hive
+// and the next, or between hatching and its first delivery.
+
+// tripWindow is how many of the most recent trips, and lifetimes, the trip
+// statistics cover.
+const tripWindow = 1000
+
+// recent holds the last tripWindow values added to it.
+type recent struct {
+	vals []float64
+	next int
+}
+
+func (r *recent) add(v float64) {
+	if len(r.vals) < tripWindow {
+		r.vals = append(r.vals, v)
+		return
+	}
+	r.vals[r.next] = v
+	r.next = (r.next + 1) % tripWindow
+}
+
+func (r *recent) mean() float64 {
+	if len(r.vals) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range r.vals {
+		sum += v
+	}
+	return sum / float64(len(r.vals))
+}
+
+func (r *recent) median() float64 {
+	if len(r.vals) == 0 {
+		return 0
+	}
+	vs := append([]float64(nil), r.vals...)
+	sort.Float64s(vs)
+	m := len(vs) / 2
+	if len(vs)%2 == 0 {
+		return (vs[m-1] + vs[m]) / 2
+	}
+	return vs[m]
+}
+
+// tripStats collects the trips ants complete, and how many each ant managed
+// before dying.
+type tripStats struct {
+	lengths recent // Distance travelled on each trip, in cells
+	lives   recent // Trips completed by each ant that died
+	total   int64
+
+	// efficiency is, for each way home with food, the fewest steps from where
+	// the food was picked up to the hive over the steps the ant took.
+	efficiency recent
+}
+
+func (t *tripStats) trip(dist float64) {
+	t.lengths.add(dist)
+	t.total++
+}
+
+// returned records an ant bringing food home in steps cells, where the
+// shortest way was best cells. It's skipped if the hive can't be reached from
+// where the food was, as can happen when walls are drawn in between.
+func (t *tripStats) returned(best, steps int) {
+	if best < 0 || steps == 0 {
+		return
+	}
+	t.efficiency.add(float64(best) / float64(steps))
+}
+
+func (t *tripStats) died(trips int) {
+	t.lives.add(float64(trips))
+}
+
+// unwrap returns the shortest way to go d along an axis of size n that wraps.
+func unwrap(d float64, n int) float64 {
+	if d > float64(n)/2 {
+		return d - float64(n)
+	}
+	if d < -float64(n)/2 {
+		return d + float64(n)
+	}
+	return d
+}
+
+// moved returns how far ant i has gone since it was at p, or (fx, fy) in
+// continuous movement. Every step on the hex grid is one cell.
+func (s *antStore) moved(as *AntScene, i int, p point, fx, fy float64) float64 {
+	var dx, dy float64
+	switch {
+	case as.st.continuous:
+		dx, dy = s.fx[i]-fx, s.fy[i]-fy
+	case as.hexGrid():
+		if s.pos[i] != p {
+			return 1
+		}
+		return 0
+	default:
+		dx, dy = float64(s.pos[i].x-p.x), float64(s.pos[i].y-p.y)
+	}
+	if as.st.wrap {
+		dx = unwrap(dx, as.field.width)
+		dy = unwrap(dy, as.field.height)
+	}
+	return math.Sqrt(dx*dx + dy*dy)
+}
+
+// meanSincePickup is the mean time since each living ant last picked up food,
+// or hatched if it hasn't yet.
+func meanSincePickup(s *antStore) float64 {
+	if s.len() == 0 {
+		return 0
+	}
+	var sum int
+	for _, t := range s.sincePickup {
+		sum += t
+	}
+	return float64(sum) / float64(s.len())
+}
+
+// summary is a one line description of the trip statistics, for the HUD.
+func (t *tripStats) summary(s *antStore) string {
+	return fmt.Sprintf("Trips: %d, length mean %.0f median %.0f, per lifetime mean %.2f median %.1f, since pickup %.0f, path efficiency %.2f",
+		t.total, t.lengths.mean(), t.lengths.median(), t.lives.mean(), t.lives.median(), meanSincePickup(s), t.efficiency.mean())
+}
+
+func tripCSVHeader() []string {
+	return []string{"tick", "trips", "length_mean", "length_median", "lifetime_trips_mean", "lifetime_trips_median", "since_pickup_mean", "efficiency_mean", "efficiency_median"}
+}
+
+func (t *tripStats) csvRecord(tick uint64, s *antStore) []string {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 3, 64) }
+	return []string{
+		strconv.FormatUint(tick, 10),
+		strconv.FormatInt(t.total, 10),
+		f(t.lengths.mean()),
+		f(t.lengths.median()),
+		f(t.lives.mean()),
+		f(t.lives.median()),
+		f(meanSincePickup(s)),
+		f(t.efficiency.mean()),
+		f(t.efficiency.median()),
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRecent(t *testing.T) {
+	var r recent
+	for _, v := range []float64{5, 1, 3, 2} {
+		r.add(v)
+	}
+	if m := r.mean(); m != 2.75 {
+		t.Errorf("Expected a mean of 2.75, got %f", m)
+	}
+	if m := r.median(); m != 2.5 {
+		t.Errorf("Expected a median of 2.5, got %f", m)
+	}
+	for i := 0; i < tripWindow; i++ {
+		r.add(10)
+	}
+	if len(r.vals) != tripWindow || r.mean() != 10 {
+		t.Errorf("Expected only the last %d values to be kept, got %d with mean %f", tripWindow, len(r.vals), r.mean())
+	}
+}
+
+func TestMoved(t *testing.T) {
+	as := testScene(t, 20, 20)
+	as.ants.add(Ant{pos: point{0, 5}})
+	if d := as.ants.moved(as, 0, point{1, 6}, 0, 0); math.Abs(d-math.Sqrt2) > 1e-9 {
+		t.Errorf("Expected a diagonal step to be sqrt 2 long, got %f", d)
+	}
+	as.st.wrap = true
+	if d := as.ants.moved(as, 0, point{19, 5}, 0, 0); d != 1 {
+		t.Errorf("Expected a step over the edge to be 1 long, got %f", d)
+	}
+}
+
+func TestTripStats(t *testing.T) {
+	as := testScene(t, 20, 20)
+	for x := 0; x < 10; x++ {
+		for y := 0; y < 10; y++ {
+			as.field.Get(x, y).Home = true
+		}
+	}
+	as.ants.add(Ant{pos: point{5, 5}, food: 2, life: 100, tripDist: 12.5, sincePickup: 40})
+	as.Step()
+
+	a := as.ants.get(0)
+	if a.trips != 1 || a.tripDist != 0 || a.sincePickup != 41 {
+		t.Errorf("Expected the ant to have finished a trip, got %+v", a)
+	}
+	if as.trips.total != 1 || len(as.trips.lengths.vals) != 1 {
+		t.Fatalf("Expected the trip to be collected, got %+v", as.trips)
+	}
+	if l := as.trips.lengths.vals[0]; l < 12.5 || l > 12.5+math.Sqrt2 {
+		t.Errorf("Expected the trip to be 12.5 cells plus the last step, got %f", l)
+	}
+}